@@ -9,6 +9,8 @@ set OLDGOPATH=%GOPATH%
 set GOPATH=%~dp0
 gofmt -w src
 go install testLib
+go install jtsigner
+go install jtvanity
 
 :end
 echo finished
@@ -14,5 +14,7 @@ OLDGOPATH="$GOPATH"
 export GOPATH="$CURDIR"
 gofmt -w src
 go install testLib
+go install jtsigner
+go install jtvanity
 export GOPATH="$OLDGOPATH"
 echo 'finished'
@@ -12,9 +12,42 @@ package constant
 //AccountPrefix AccountPrefix
 const AccountPrefix uint8 = 0
 
+//NodePublicPrefix 节点公钥的 base58 版本前缀
+const NodePublicPrefix uint8 = 28
+
+//AccountPublicPrefix 账户公钥的 base58 版本前缀
+const AccountPublicPrefix uint8 = 35
+
 //SeedPrefix SeedPrefix
 const SeedPrefix uint8 = 33
 
+//FamilyGeneratorPrefix 账户族公开生成器的 base58 版本前缀
+const FamilyGeneratorPrefix uint8 = 41
+
+//Ed25519SeedPrefix ed25519 私钥的 3 字节版本前缀
+var Ed25519SeedPrefix = []byte{0x01, 0xE1, 0x4B}
+
+//Ed25519PubKeyPrefix ed25519 公钥前缀，补齐为 33 字节
+const Ed25519PubKeyPrefix uint8 = 0xED
+
+//TxFlagFullyCanonicalSig 交易的通用标志，要求签名为规范的 low-S 签名，本地签名的交易都会设置
+const TxFlagFullyCanonicalSig uint32 = 0x80000000
+
+//MessageSignPrefix 消息签名前缀，签名数据为 前缀 + 消息长度（十进制）+ 消息，首字节 0x19 与交易签名前缀区分，签名不能当作交易签名使用
+const MessageSignPrefix = "\x19Jingtum Signed Message:\n"
+
+//HashPrefixTxSign 交易签名哈希前缀 STX
+const HashPrefixTxSign uint32 = 0x53545800
+
+//HashPrefixTxMultiSign 多重签名哈希前缀 SMT
+const HashPrefixTxMultiSign uint32 = 0x534D5400
+
+//HashPrefixTransactionID 交易 ID 哈希前缀 TXN
+const HashPrefixTransactionID uint32 = 0x54584E00
+
+//HashPrefixLeafNode 账本状态树叶子节点哈希前缀 MLN
+const HashPrefixLeafNode uint32 = 0x4D4C4E00
+
 //RegexCurrency RegexCurrency
 const RegexCurrency = "^([a-zA-Z0-9]{3,6}|[A-F0-9]{40})$"
 
@@ -80,3 +113,12 @@ const EventPathFind = "path_find"
 
 //EventServerStatus 服务状态事件
 const EventServerStatus = "server_status"
+
+//EventConnecting 正在连接服务事件，断线后每次重连前触发
+const EventConnecting = "connecting"
+
+//EventOnline 服务连接成功事件，断线重连成功后也会触发
+const EventOnline = "online"
+
+//EventOffline 服务连接断开事件，重连失败时也会触发
+const EventOffline = "offline"
@@ -30,6 +30,10 @@ var (
 
 	ERR_SERVER_NOT_READY = errors.New("server not ready")
 
+	ERR_SERVER_DISCONNECTED = errors.New("server disconnected before the response was received.")
+
+	ERR_SERVER_NOT_SUBSCRIBABLE = errors.New("subscription requires a websocket server.")
+
 	//支付相关错误码
 	ERR_PAYMENT_INVALID_SRC_ADDR = errors.New("invalid source address.")
 
@@ -44,4 +48,36 @@ var (
 	ERR_PAYMENT_OUT_OF_MEMO_LEN = errors.New("The length of Memo shoule be less than or equal 2048.")
 
 	ERR_PAYMENT_INVALID_SECRET = errors.New("invalid secret.")
+
+	//签名验证相关错误码
+	ERR_TX_NOT_SIGNED = errors.New("transaction is not signed.")
+
+	ERR_TX_INVALID_SIGNATURE = errors.New("invalid transaction signature.")
+
+	ERR_TX_SIGNER_NOT_AUTHORIZED = errors.New("signing key is not the account key or its regular key.")
+
+	ERR_TX_SIGNER_REQUIRED = errors.New("secret or signer is required to sign transaction.")
+
+	ERR_TX_SERVER_SIGN_ONLY = errors.New("SignerListSet can only be signed by the server.")
+
+	ERR_TX_LOCAL_SIGN_REQUIRED = errors.New("transaction hash is only known before submit when signed locally.")
+
+	//消息签名相关错误码
+	ERR_MESSAGE_INVALID_SIGNATURE = errors.New("invalid message signature.")
+
+	ERR_LOGIN_CHALLENGE_EXPIRED = errors.New("login challenge expired.")
+
+	//账户族相关错误码
+	ERR_WALLET_NO_SECRET = errors.New("derived account has no secret, back up the family secret and index.")
+
+	ERR_FAMILY_WATCH_ONLY = errors.New("watch-only family can not derive private keys.")
+
+	ERR_FAMILY_UNSUPPORTED_KEY = errors.New("account family is only supported by secp256k1 secrets.")
+
+	//钱包文件相关错误码
+	ERR_KEYSTORE_INVALID = errors.New("invalid keystore.")
+
+	ERR_KEYSTORE_PASSWORD = errors.New("wrong password or corrupted keystore.")
+
+	ERR_KEYSTORE_NOT_FOUND = errors.New("keystore not found.")
 )
@@ -148,6 +148,7 @@ var (
 		"TemplateEntry":       &KeyValuePair{14, 9},
 		"Memo":                &KeyValuePair{14, 10},
 		"Arg":                 &KeyValuePair{14, 11},
+		"Signer":              &KeyValuePair{14, 16}, //多重签名交易 TxnSignatures 中的签名者，编码同 rippled 的 sfSigner
 		"SigningAccounts":     &KeyValuePair{15, 2},
 		"TxnSignatures":       &KeyValuePair{15, 3},
 		"Signatures":          &KeyValuePair{15, 4},
@@ -0,0 +1,98 @@
+/**
+ *
+ * ed25519 秘钥对
+ *
+ * @FileName: ed25519KeyPair.go
+ */
+
+package ed25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	jtConst "jingtumlib/constant"
+	jtCrypto "jingtumlib/crypto"
+	jtUtils "jingtumlib/utils"
+)
+
+//Ed25519KeyPair Ed25519KeyPair
+type Ed25519KeyPair struct{}
+
+//PrivateKey ed25519 私钥
+type PrivateKey struct {
+	key ed25519.PrivateKey
+}
+
+//DeriveKeyPair 根据私钥生成秘钥对，私钥种子为 16 字节熵的 sha512 half
+func (*Ed25519KeyPair) DeriveKeyPair(secret string) (jtCrypto.PrivateKey, error) {
+	entropy, err := jtUtils.DecodeB58Prefix(jtConst.Ed25519SeedPrefix, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entropy) != 16 {
+		return nil, fmt.Errorf("invalid input size")
+	}
+
+	sh512 := jtUtils.NewSha512()
+	sh512.Add(entropy)
+
+	return &PrivateKey{key: ed25519.NewKeyFromSeed(sh512.Finish256())}, nil
+}
+
+//GenerateSeed 生成私钥
+func (*Ed25519KeyPair) GenerateSeed() (string, error) {
+	seedBytes := make([]byte, 16)
+	_, err := io.ReadFull(rand.Reader, seedBytes)
+	if err != nil {
+		return "", fmt.Errorf("Reading random reader: %s", err.Error())
+	}
+	return jtUtils.EncodeB58Prefix(jtConst.Ed25519SeedPrefix, seedBytes), nil
+}
+
+//CheckAddress 验证地址
+func (*Ed25519KeyPair) CheckAddress(address string) bool {
+	_, err := jtUtils.DecodeB58(jtConst.AccountPrefix, address)
+
+	return err == nil
+}
+
+//KeyType 签名算法类型
+func (priv *PrivateKey) KeyType() jtCrypto.KeyType {
+	return jtCrypto.Ed25519
+}
+
+//PublicKeyBytes 0xED + 32字节公钥
+func (priv *PrivateKey) PublicKeyBytes() []byte {
+	pub := priv.key.Public().(ed25519.PublicKey)
+	return append([]byte{jtConst.Ed25519PubKeyPrefix}, pub...)
+}
+
+//BytesToHex 16进制公钥
+func (priv *PrivateKey) BytesToHex() string {
+	return strings.ToUpper(hex.EncodeToString(priv.PublicKeyBytes()))
+}
+
+//ToAddress 公钥转成钱包地址
+func (priv *PrivateKey) ToAddress() string {
+	return jtCrypto.AddressFromPublicKey(priv.PublicKeyBytes())
+}
+
+//Sign 直接对签名数据签名，不做哈希
+func (priv *PrivateKey) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(priv.key, message), nil
+}
+
+//Verify 用 0xED 开头的 33 字节公钥验证签名数据的签名
+func Verify(pubKey []byte, message []byte, signature []byte) bool {
+	if len(pubKey) != ed25519.PublicKeySize+1 || pubKey[0] != jtConst.Ed25519PubKeyPrefix || len(signature) != ed25519.SignatureSize {
+		return false
+	}
+
+	return ed25519.Verify(ed25519.PublicKey(pubKey[1:]), message, signature)
+}
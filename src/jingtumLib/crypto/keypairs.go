@@ -13,16 +13,123 @@
 package crypto
 
 import (
-	"jingtumlib/crypto/secp256k1"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	jtConst "jingtumlib/constant"
+	jtEncode "jingtumlib/encoding"
+	jtUtils "jingtumlib/utils"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+//KeyType 签名算法类型
+type KeyType string
+
+const (
+	//Secp256k1 secp256k1 算法，默认算法
+	Secp256k1 KeyType = "secp256k1"
+	//Ed25519 ed25519 算法
+	Ed25519 KeyType = "ed25519"
 )
 
+//PrivateKey 与签名算法无关的私钥接口
+type PrivateKey interface {
+	//签名算法类型
+	KeyType() KeyType
+	//33字节公钥，ed25519 公钥以 0xED 开头
+	PublicKeyBytes() []byte
+	//16进制公钥
+	BytesToHex() string
+	//钱包地址
+	ToAddress() string
+	//对签名数据（哈希前缀 + 序列化数据）签名。secp256k1 先做 sha512 half 哈希，ed25519 直接签名
+	Sign(message []byte) ([]byte, error)
+}
+
+//HashSigner 可以直接对 32 字节哈希签名的私钥，如 secp256k1 对签名数据的 sha512 half 签名，
+//远程签名时只需传递哈希。ed25519 对签名数据本身签名，不支持
+type HashSigner interface {
+	SignHash(hash []byte) ([]byte, error)
+}
+
 //KeyPair KeyPair
 type KeyPair interface {
 	//根据私钥获取秘钥对
-	DeriveKeyPair(secret string) (*secp256k1.PrivateKey, error)
+	DeriveKeyPair(secret string) (PrivateKey, error)
 
 	//地址格式验证
 	CheckAddress(address string) bool
 	//生成私钥
 	GenerateSeed() (string, error)
 }
+
+//SecretKeyType 根据私钥前缀判断签名算法，ed25519 私钥以 3 字节前缀编码，其余按 secp256k1 处理
+func SecretKeyType(secret string) KeyType {
+	decodedBytes, err := jtEncode.Base58Decode(secret, jtEncode.JingTumAlphabet)
+	if err != nil {
+		return Secp256k1
+	}
+
+	prefix := jtConst.Ed25519SeedPrefix
+	if len(decodedBytes) == len(prefix)+16+4 && bytes.Equal(decodedBytes[:len(prefix)], prefix) {
+		return Ed25519
+	}
+
+	return Secp256k1
+}
+
+//AddressFromPublicKey 33字节公钥转成钱包地址，secp256k1 和 ed25519 相同，对公钥做 sha256 + ripemd160
+func AddressFromPublicKey(pubKey []byte) string {
+	return jtUtils.EncodeB58(jtConst.AccountPrefix, AccountIDFromPublicKey(pubKey))
+}
+
+//AccountIDFromPublicKey 33字节公钥转成 20 字节 AccountID，即 ripemd160(sha256(公钥))，地址为 AccountID 的 base58 编码
+func AccountIDFromPublicKey(pubKey []byte) []byte {
+	pubHash := sha256.Sum256(pubKey)
+
+	ripemd160H := ripemd160.New()
+	ripemd160H.Write(pubHash[:])
+
+	return ripemd160H.Sum(nil)
+}
+
+//SecretEntropy 私钥解码成 16 字节熵，同时返回私钥的签名算法
+func SecretEntropy(secret string) ([]byte, KeyType, error) {
+	keyType := SecretKeyType(secret)
+
+	var entropy []byte
+	var err error
+	if keyType == Ed25519 {
+		entropy, err = jtUtils.DecodeB58Prefix(jtConst.Ed25519SeedPrefix, secret)
+	} else {
+		entropy, err = jtUtils.DecodeB58(jtConst.SeedPrefix, secret)
+	}
+
+	if err != nil {
+		return nil, keyType, err
+	}
+
+	if len(entropy) != 16 {
+		return nil, keyType, fmt.Errorf("invalid input size")
+	}
+
+	return entropy, keyType, nil
+}
+
+//EncodeSecret 16 字节熵按签名算法编码成私钥，ed25519 私钥使用 3 字节前缀
+func EncodeSecret(entropy []byte, keyType KeyType) (string, error) {
+	if len(entropy) != 16 {
+		return "", fmt.Errorf("invalid input size")
+	}
+
+	switch keyType {
+	case Secp256k1:
+		return jtUtils.EncodeB58(jtConst.SeedPrefix, entropy), nil
+	case Ed25519:
+		return jtUtils.EncodeB58Prefix(jtConst.Ed25519SeedPrefix, entropy), nil
+	}
+
+	return "", fmt.Errorf("Unsupported key type %s", keyType)
+}
@@ -10,20 +10,25 @@
  * Copyright@2013 版权所有
  */
 
-package crypto
+package crypto_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"flag"
 	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/crypto/ed25519"
 	"jingtumlib/crypto/secp256k1"
 	"jingtumlib/utils"
 	"math/big"
 	"os"
+	"strings"
 	"testing"
 )
 
 var (
-	keyPair KeyPair = &secp256k1.Secp256KeyPair{}
+	keyPair crypto.KeyPair = &secp256k1.Secp256KeyPair{}
 )
 
 func Test_sha256Util(t *testing.T) {
@@ -45,13 +50,225 @@ func Test_encode(t *testing.T) {
 }
 
 func Test_deriveKeyPair(t *testing.T) {
-	pri, _ := keyPair.DeriveKeyPair("snsYqv2FsYLuibE9TGHdG5x5V5Qcn")
+	key, _ := keyPair.DeriveKeyPair("snsYqv2FsYLuibE9TGHdG5x5V5Qcn")
+	pri := key.(*secp256k1.PrivateKey)
 	t.Log("private key : ", pri.D)
 	t.Log("public key : ", new(big.Int).SetBytes(pri.PublicKey.ToBytes()))
 	t.Log("public address : ", pri.PublicKey.ToAddress())
 	t.Log("public key to hex : ", pri.PublicKey.BytesToHex())
 }
 
+func Test_ed25519KeyPair(t *testing.T) {
+	//熵为 0x01...0x10 的私钥
+	secret := "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"
+	if crypto.SecretKeyType(secret) != crypto.Ed25519 {
+		t.Fatalf("SecretKeyType(%s) is %s", secret, crypto.SecretKeyType(secret))
+	}
+
+	if crypto.SecretKeyType("snsYqv2FsYLuibE9TGHdG5x5V5Qcn") != crypto.Secp256k1 {
+		t.Fatalf("SecretKeyType of secp256k1 secret should be secp256k1")
+	}
+
+	var edKeyPair crypto.KeyPair = &ed25519.Ed25519KeyPair{}
+	pri, err := edKeyPair.DeriveKeyPair(secret)
+	if err != nil {
+		t.Fatalf("DeriveKeyPair fail : %s", err.Error())
+	}
+
+	if pri.BytesToHex() != "ED01FA53FA5A7E77798F882ECE20B1ABC00BB358A9E55A202D0D0676BD0CE37A63" {
+		t.Fatalf("Public key %s error", pri.BytesToHex())
+	}
+
+	if pri.ToAddress() != "jLUEXYuLiQptky37CqLcm9USQpPiz5jkpD" {
+		t.Fatalf("Address %s error", pri.ToAddress())
+	}
+
+	signature, _ := pri.Sign([]byte("test message"))
+	if strings.ToUpper(hex.EncodeToString(signature)) != "CB199E1BFD4E3DAA105E4832EEDFA36413E1F44205E4EFB9E27E826044C21E3E2E848BBC8195E8959BADF887599B7310AD1B7047EF11B682E0D068F73749750E" {
+		t.Fatalf("Signature %X error", signature)
+	}
+
+	if _, err := edKeyPair.DeriveKeyPair("snsYqv2FsYLuibE9TGHdG5x5V5Qcn"); err == nil {
+		t.Fatalf("DeriveKeyPair of secp256k1 secret should fail")
+	}
+
+	seed, err := edKeyPair.GenerateSeed()
+	if err != nil || crypto.SecretKeyType(seed) != crypto.Ed25519 {
+		t.Fatalf("GenerateSeed %s, %v", seed, err)
+	}
+}
+
+//Test_secp256k1Canonical secp256k1 签名确定且为 low-S 规范签名
+func Test_secp256k1Canonical(t *testing.T) {
+	pri, _ := keyPair.DeriveKeyPair("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	message := []byte("STX message")
+	signature, err := pri.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign fail : %s", err.Error())
+	}
+
+	//RFC6979 确定性签名
+	for i := 0; i < 3; i++ {
+		if again, _ := pri.Sign(message); hex.EncodeToString(again) != hex.EncodeToString(signature) {
+			t.Fatalf("Signature is not deterministic : %X, %X", signature, again)
+		}
+	}
+
+	if !secp256k1.IsCanonicalSignature(signature, true) || !secp256k1.Verify(pri.PublicKeyBytes(), message, signature, true) {
+		t.Fatalf("Signature %X is not fully canonical", signature)
+	}
+
+	//S 替换成 N - S 后仍是有效签名，但不是 low-S
+	rLen := int(signature[3])
+	r := signature[4 : 4+rLen]
+	sValue := new(big.Int).SetBytes(signature[6+rLen:])
+	n, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	high := new(big.Int).Sub(n, sValue).Bytes()
+	if high[0]&0x80 != 0 {
+		high = append([]byte{0}, high...)
+	}
+	highS := derSignature(r, high)
+
+	if !secp256k1.IsCanonicalSignature(highS, false) || secp256k1.IsCanonicalSignature(highS, true) {
+		t.Fatalf("High S signature %X canonicality error", highS)
+	}
+
+	if !secp256k1.Verify(pri.PublicKeyBytes(), message, highS, false) || secp256k1.Verify(pri.PublicKeyBytes(), message, highS, true) {
+		t.Fatalf("High S signature %X verify error", highS)
+	}
+
+	invalids := [][]byte{
+		derSignature(append([]byte{0}, r...), sValue.Bytes()),
+		derSignature([]byte{0x80}, sValue.Bytes()),
+		derSignature(r, []byte{0}),
+		derSignature(r, n.Bytes()),
+		append(append([]byte{}, signature...), 0),
+		signature[:len(signature)-1],
+	}
+	for _, sig := range invalids {
+		if secp256k1.IsCanonicalSignature(sig, false) {
+			t.Fatalf("Signature %X should not be canonical", sig)
+		}
+	}
+}
+
+//Test_secp256k1Family 账户族派生的账户，序号 0 即私钥本身的账户，只读账户族派生相同的公钥
+func Test_secp256k1Family(t *testing.T) {
+	secret := "ssc5eiFivvU2otV6bSYmJeZrAsQK3"
+	pubKeys := []string{
+		"021388E6428615BFF60744C6936E69BFDC603F9F2CA3D473B48B4A20DE171D1F04",
+		"02E573E7345A7D029FF6F7A7915D1A62B3CA1A3851B81E6BA0B24F5D582DD06EC8",
+		"024E9262FF0EBB7C651ADD69FF84AB4D7883803405EC5FE78D191EA7ED2580800B",
+	}
+
+	family, err := secp256k1.NewFamily(secret)
+	if err != nil {
+		t.Fatalf("NewFamily fail : %s", err.Error())
+	}
+
+	generator := family.PublicGenerator()
+	if generator != "fhq1tyGzSPRjmLQxpz9fSqZe62gTXmkLV3qEqZnkQVVZhXwEgaNb" {
+		t.Fatalf("Public generator %s", generator)
+	}
+
+	public, err := secp256k1.NewPublicFamily(generator)
+	if err != nil || !public.IsWatchOnly() || family.IsWatchOnly() {
+		t.Fatalf("NewPublicFamily fail : %v", err)
+	}
+
+	for i, pubKey := range pubKeys {
+		priv, err := secp256k1.DeriveAccount(secret, uint32(i))
+		if err != nil || priv.BytesToHex() != pubKey {
+			t.Fatalf("DeriveAccount(%d) = %s, err %v", i, priv.BytesToHex(), err)
+		}
+
+		if public.PublicKey(uint32(i)).BytesToHex() != pubKey {
+			t.Fatalf("Watch-only PublicKey(%d) = %s", i, public.PublicKey(uint32(i)).BytesToHex())
+		}
+	}
+
+	key, _ := keyPair.DeriveKeyPair(secret)
+	if key.BytesToHex() != pubKeys[0] {
+		t.Fatalf("DeriveKeyPair %s", key.BytesToHex())
+	}
+
+	if _, err := public.PrivateKey(1); err == nil {
+		t.Fatalf("Watch-only family should not derive private key")
+	}
+
+	//x = 5 不在曲线上
+	notOnCurve := append([]byte{0x02}, make([]byte, 31)...)
+	notOnCurve = append(notOnCurve, 5)
+	invalid := []string{
+		utils.EncodeB58(constant.FamilyGeneratorPrefix, notOnCurve),
+		utils.EncodeB58(constant.FamilyGeneratorPrefix, notOnCurve[:32]),
+		"jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+	}
+	for _, generator := range invalid {
+		if _, err := secp256k1.NewPublicFamily(generator); err == nil {
+			t.Fatalf("Invalid generator %s should fail", generator)
+		}
+	}
+}
+
+//Test_secp256k1PublicKey 压缩公钥解压缩，非压缩公钥须在曲线上
+func Test_secp256k1PublicKey(t *testing.T) {
+	compressed, _ := hex.DecodeString("021388E6428615BFF60744C6936E69BFDC603F9F2CA3D473B48B4A20DE171D1F04")
+	uncompressed, _ := hex.DecodeString("041388E6428615BFF60744C6936E69BFDC603F9F2CA3D473B48B4A20DE171D1F04918D43DD9357B5340AE1D0F4FF9143521B76E90031C7C12449640D780EE6C810")
+
+	pub, err := secp256k1.ParsePublicKey(compressed)
+	if err != nil || !pub.IsValid() {
+		t.Fatalf("ParsePublicKey fail : %v", err)
+	}
+	if !bytes.Equal(pub.ToUncompressedBytes(), uncompressed) {
+		t.Fatalf("Uncompressed %X", pub.ToUncompressedBytes())
+	}
+
+	pub, err = secp256k1.ParsePublicKey(uncompressed)
+	if err != nil || !bytes.Equal(pub.ToBytes(), compressed) {
+		t.Fatalf("ParsePublicKey(uncompressed) fail : %v", err)
+	}
+
+	//Y 为奇数时前缀为 03
+	odd := append([]byte{0x03}, compressed[1:]...)
+	pub, err = secp256k1.ParsePublicKey(odd)
+	if err != nil || pub.Y.Bit(0) != 1 || !bytes.Equal(pub.ToBytes(), odd) {
+		t.Fatalf("ParsePublicKey(03) fail : %v", err)
+	}
+
+	notOnCurve := append([]byte{0x02}, make([]byte, 32)...)
+	notOnCurve[32] = 5
+	tampered := append([]byte{}, uncompressed...)
+	tampered[64] ^= 0x01
+	for _, invalid := range [][]byte{nil, compressed[:32], notOnCurve, tampered, append([]byte{0x05}, compressed[1:]...)} {
+		if secp256k1.IsValidPublicKey(invalid) {
+			t.Fatalf("Invalid public key %X", invalid)
+		}
+	}
+
+	accountID := crypto.AccountIDFromPublicKey(compressed)
+	if strings.ToUpper(hex.EncodeToString(accountID)) != "AA36C7655C4E4136A37D11A2A487DFDB0AE3ACD1" {
+		t.Fatalf("AccountID %X", accountID)
+	}
+}
+
+//derSignature 按 DER 编码 R、S，不做规范化
+func derSignature(r []byte, s []byte) []byte {
+	sig := []byte{0x30, byte(4 + len(r) + len(s)), 0x02, byte(len(r))}
+	sig = append(sig, r...)
+	sig = append(sig, 0x02, byte(len(s)))
+	return append(sig, s...)
+}
+
+//BenchmarkDeriveKeyPair secp256k1 私钥派生密钥
+func BenchmarkDeriveKeyPair(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := keyPair.DeriveKeyPair("ssc5eiFivvU2otV6bSYmJeZrAsQK3"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	flag.Set("alsologtostderr", "true")
 	flag.Set("log_dir", "/tmp")
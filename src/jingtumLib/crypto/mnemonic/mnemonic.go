@@ -0,0 +1,99 @@
+/**
+ *
+ * 助记词，私钥熵与 BIP39 英文单词之间的转换
+ *
+ * @FileName: mnemonic.go
+ */
+
+package mnemonic
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//ErrChecksum 助记词校验码错误，通常是单词抄错或顺序错误
+var ErrChecksum = errors.New("invalid mnemonic checksum")
+
+//wordIndex 单词到序号的索引
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(english))
+	for i, word := range english {
+		index[word] = i
+	}
+	return index
+}()
+
+//EntropyToMnemonic 熵转成助记词，熵为 16 至 32 字节且为 4 的倍数，井通私钥的 16 字节熵对应 12 个单词。
+//熵之后追加 sha256(熵) 的前 len(熵)/4 位作为校验码，每 11 位对应词表中的一个单词
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("invalid entropy size %d", len(entropy))
+	}
+
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), hash[0])
+	count := (len(entropy)*8 + len(entropy)/4) / 11
+
+	words := make([]string, count)
+	for i := range words {
+		words[i] = english[readBits(data, i*11)]
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+//MnemonicToEntropy 助记词转成熵，单词不区分大小写，以空白分隔。单词不在词表中或校验码错误时返回错误
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("invalid mnemonic size %d", len(words))
+	}
+
+	bits := len(words) * 11
+	data := make([]byte, (bits+7)/8)
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("unknown mnemonic word %q at position %d", word, i+1)
+		}
+		writeBits(data, i*11, index)
+	}
+
+	size := bits * 32 / 33 / 8
+	entropy := data[:size]
+	hash := sha256.Sum256(entropy)
+	checksumBits := uint(size / 4)
+	if data[size]>>(8-checksumBits) != hash[0]>>(8-checksumBits) {
+		return nil, ErrChecksum
+	}
+
+	return entropy, nil
+}
+
+//IsValidMnemonic 助记词合法性验证
+func IsValidMnemonic(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+//readBits 读取 data 从第 offset 位开始的 11 位
+func readBits(data []byte, offset int) int {
+	value := 0
+	for i := offset; i < offset+11; i++ {
+		value = value<<1 | int(data[i/8]>>(7-uint(i%8))&1)
+	}
+	return value
+}
+
+//writeBits 把 value 的低 11 位写到 data 从第 offset 位开始的位置
+func writeBits(data []byte, offset int, value int) {
+	for i := 0; i < 11; i++ {
+		if value>>(10-uint(i))&1 == 1 {
+			pos := offset + i
+			data[pos/8] |= 1 << (7 - uint(pos%8))
+		}
+	}
+}
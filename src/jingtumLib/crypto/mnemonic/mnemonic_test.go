@@ -0,0 +1,89 @@
+/**
+ *
+ * 助记词测试类
+ *
+ * @FileName: mnemonic_test.go
+ */
+
+package mnemonic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+//Test_Wordlist 词表按字母排序，前 4 个字母各不相同
+func Test_Wordlist(t *testing.T) {
+	if len(english) != 2048 {
+		t.Fatalf("Wordlist size %d", len(english))
+	}
+
+	prefixes := make(map[string]bool, len(english))
+	for i, word := range english {
+		if i > 0 && english[i-1] >= word {
+			t.Fatalf("Wordlist is not sorted at %s", word)
+		}
+
+		prefix := word
+		if len(prefix) > 4 {
+			prefix = prefix[:4]
+		}
+		if prefixes[prefix] {
+			t.Fatalf("Duplicate prefix %s", prefix)
+		}
+		prefixes[prefix] = true
+	}
+}
+
+//Test_Mnemonic BIP39 测试向量
+func Test_Mnemonic(t *testing.T) {
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+	}{
+		{"00000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f", "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{"80808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage above"},
+		{"ffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"},
+		{"9e885d952ad362caeb4efe34a8e91bd2", "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic"},
+		{"0000000000000000000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+		{"68a79eaca2324873eacc50cb9c6eca8cc68ea5d936f98787c60c7ebc74e6ce7c", "hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length"},
+	}
+
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := EntropyToMnemonic(entropy)
+		if err != nil || mnemonic != v.mnemonic {
+			t.Fatalf("EntropyToMnemonic(%s) = %s, err %v", v.entropy, mnemonic, err)
+		}
+
+		decoded, err := MnemonicToEntropy(v.mnemonic)
+		if err != nil || !bytes.Equal(decoded, entropy) {
+			t.Fatalf("MnemonicToEntropy(%s) = %x, err %v", v.mnemonic, decoded, err)
+		}
+	}
+
+	//大小写和多余空白不影响
+	if !IsValidMnemonic("  Legal winner THANK year wave sausage worth useful legal winner thank yellow\n") {
+		t.Fatalf("Mixed case mnemonic is invalid")
+	}
+
+	//交换两个单词后校验码错误
+	if _, err := MnemonicToEntropy("winner legal thank year wave sausage worth useful legal winner thank yellow"); err != ErrChecksum {
+		t.Fatalf("Swapped words err %v", err)
+	}
+
+	if _, err := MnemonicToEntropy("legal winner thank year wave sausage worth useful legal winner thank yelow"); err == nil || !strings.Contains(err.Error(), "yelow") {
+		t.Fatalf("Unknown word err %v", err)
+	}
+
+	if _, err := MnemonicToEntropy("legal winner thank"); err == nil {
+		t.Fatalf("Short mnemonic should fail")
+	}
+
+	if _, err := EntropyToMnemonic(make([]byte, 15)); err == nil {
+		t.Fatalf("Invalid entropy size should fail")
+	}
+}
@@ -17,10 +17,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
 )
 
 /* We gotta do a lot ourselves because golang's crypto/elliptic uses curves
- * with a = -3 hardcoded */
+ * with a = -3 hardcoded. Scalar multiplication is delegated to btcec, which
+ * implements secp256k1 (a = 0) with field arithmetic in Jacobian coordinates. */
 
 /* See SEC2 pg.9 http://www.secg.org/collateral/sec2_final.pdf */
 
@@ -76,16 +79,8 @@ func subMod(x *big.Int, y *big.Int, p *big.Int) (z *big.Int) {
 
 // mulMod computes z = (x * y) % p.
 func mulMod(x *big.Int, y *big.Int, p *big.Int) (z *big.Int) {
-	n := new(big.Int).Set(x)
-	z = big.NewInt(0)
-
-	for i := 0; i < y.BitLen(); i++ {
-		if y.Bit(i) == 1 {
-			z = addMod(z, n, p)
-		}
-		n = addMod(n, n, p)
-	}
-
+	z = new(big.Int).Mul(x, y)
+	z.Mod(z, p)
 	return z
 }
 
@@ -247,38 +242,62 @@ func (p Point) Compression() (b []byte) {
 	return append([]byte{0x03}, paddedX...)
 }
 
-// ScalarMult computes Q = k * P on EllipticCurve ec.
+// Decompress decompresses a 33 bytes compressed point (02 or 03 + X) on EllipticCurve ec,
+// and checks that the point is on the curve.
+func (ec *EllipticCurve) Decompress(b []byte) (P Point, err error) {
+	if len(b) != 33 || (b[0] != 0x02 && b[0] != 0x03) {
+		return P, fmt.Errorf("invalid compressed point")
+	}
+
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(ec.P) >= 0 {
+		return P, fmt.Errorf("invalid compressed point")
+	}
+
+	/* y**2 = x**3 + a*x + b  % p */
+	rhs := addMod(
+		addMod(
+			expMod(x, big.NewInt(3), ec.P),
+			mulMod(ec.A, x, ec.P), ec.P),
+		ec.B, ec.P)
+	y := sqrtMod(rhs, ec.P)
+	if mulMod(y, y, ec.P).Cmp(rhs) != 0 {
+		return P, fmt.Errorf("point is not on curve")
+	}
+
+	if y.Bit(0) != uint(b[0]&0x01) {
+		y = subMod(ec.P, y, ec.P)
+	}
+
+	P.X = x
+	P.Y = y
+	return P, nil
+}
+
+// ScalarMult computes Q = k * P on secp256k1.
+// P is a public point (e.g. a public generator), the multiplication is delegated to btcec.
 func (ec *EllipticCurve) ScalarMult(k *big.Int, P Point) (Q Point) {
-	/* Note: this function is not constant time, due to the branching nature of
-	 * the underlying point Add() function. */
-
-	/* Montgomery Ladder Point Multiplication
-	 *
-	 * Implementation based on pseudocode here:
-	 * See https://en.wikipedia.org/wiki/Elliptic_curve_point_multiplication#Montgomery_ladder */
-
-	var R0 Point
-	var R1 Point
-
-	R0.X = nil
-	R0.Y = nil
-	R1.X = new(big.Int).Set(P.X)
-	R1.Y = new(big.Int).Set(P.Y)
-
-	for i := ec.N.BitLen() - 1; i >= 0; i-- {
-		if k.Bit(i) == 0 {
-			R1 = ec.Add(R0, R1)
-			R0 = ec.Add(R0, R0)
-		} else {
-			R0 = ec.Add(R0, R1)
-			R1 = ec.Add(R1, R1)
-		}
+	if ec.IsInfinity(P) {
+		return Q
 	}
 
-	return R0
+	return fromAffine(btcec.S256().ScalarMult(P.X, P.Y, k.Bytes()))
 }
 
-// ScalarBaseMult computes Q = k * G on EllipticCurve ec.
+// ScalarBaseMult computes Q = k * G on secp256k1, k is usually a private key.
+// btcec adds one precomputed point per byte of k in Jacobian coordinates, which is much faster
+// than double-and-add over big.Int. It is not constant time and makes no side-channel guarantees.
 func (ec *EllipticCurve) ScalarBaseMult(k *big.Int) (Q Point) {
-	return ec.ScalarMult(k, ec.G)
+	return fromAffine(btcec.S256().ScalarBaseMult(k.Bytes()))
+}
+
+// fromAffine converts btcec affine coordinates to a Point, btcec uses (0,0) for infinity.
+func fromAffine(x *big.Int, y *big.Int) (Q Point) {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return Q
+	}
+
+	Q.X = x
+	Q.Y = y
+	return Q
 }
@@ -0,0 +1,88 @@
+/**
+ *
+ * 账户族，同一个种子派生多个账户
+ *
+ * @FileName: family.go
+ */
+
+package secp256k1
+
+import (
+	"fmt"
+	"math/big"
+
+	jtConst "jingtumlib/constant"
+	jtUtils "jingtumlib/utils"
+)
+
+//Family 账户族。种子派生私有生成器 privateGen 和公开生成器 publicGen = privateGen * G，
+//序号为 index 的账户私钥为 privateGen + additional(publicGen, index)，公钥为 publicGen + additional * G。
+//只有公开生成器的账户族（只读）可以派生公钥和地址，不能派生私钥
+type Family struct {
+	privateGen *big.Int
+	publicGen  Point
+}
+
+//NewFamily 根据 secp256k1 私钥创建账户族，序号 0 的账户即私钥本身的账户。私钥须为 16 字节种子，校验码正确
+func NewFamily(secret string) (*Family, error) {
+	seed, err := jtUtils.DecodeB58(jtConst.SeedPrefix, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seed) != 16 {
+		return nil, fmt.Errorf("invalid seed size %d", len(seed))
+	}
+
+	return newFamily(seed), nil
+}
+
+func newFamily(seed []byte) *Family {
+	privateGen := scalarMultiple(seed)
+	return &Family{privateGen: privateGen, publicGen: ec.ScalarBaseMult(privateGen)}
+}
+
+//NewPublicFamily 根据公开生成器（PublicGenerator 的 base58 编码）创建只读账户族
+func NewPublicFamily(generator string) (*Family, error) {
+	decodedBytes, err := jtUtils.DecodeB58(jtConst.FamilyGeneratorPrefix, generator)
+	if err != nil {
+		return nil, err
+	}
+
+	publicGen, err := ec.Decompress(decodedBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Family{publicGen: publicGen}, nil
+}
+
+//PublicGenerator 公开生成器的 base58 编码，可以交给只读的服务派生地址，不能派生私钥
+func (family *Family) PublicGenerator() string {
+	return jtUtils.EncodeB58(jtConst.FamilyGeneratorPrefix, family.publicGen.Compression())
+}
+
+//IsWatchOnly 是否为只读账户族
+func (family *Family) IsWatchOnly() bool {
+	return family.privateGen == nil
+}
+
+//PrivateKey 派生序号为 index 的账户私钥
+func (family *Family) PrivateKey(index uint32) (*PrivateKey, error) {
+	if family.IsWatchOnly() {
+		return nil, fmt.Errorf("watch-only family can not derive private key")
+	}
+
+	var priv PrivateKey
+	priv.D = addMod(scalarMultipleDiscrim(family.publicGen.Compression(), index), family.privateGen, ec.N)
+	Q := ec.ScalarBaseMult(priv.D)
+	priv.X = Q.X
+	priv.Y = Q.Y
+	return &priv, nil
+}
+
+//PublicKey 派生序号为 index 的账户公钥，只读账户族也可以派生
+func (family *Family) PublicKey(index uint32) *PublicKey {
+	additional := scalarMultipleDiscrim(family.publicGen.Compression(), index)
+	return &PublicKey{ec.Add(family.publicGen, ec.ScalarBaseMult(additional))}
+}
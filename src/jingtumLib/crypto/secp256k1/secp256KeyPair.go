@@ -25,7 +25,7 @@ import (
 	"strings"
 
 	jtConst "jingtumlib/constant"
-	jtEncode "jingtumlib/encoding"
+	jtCrypto "jingtumlib/crypto"
 	jtUtils "jingtumlib/utils"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -67,30 +67,29 @@ type PrivateKey struct {
 	D *big.Int
 }
 
-/**
- *  根据 seed byte 获得私钥
- */
-func derivePrivateKey(seed []byte) *big.Int {
-	privateGen := scalarMultiple(seed)
-	publickGen := ec.ScalarBaseMult(privateGen).Compression()
-	pb := scalarMultipleDiscrim(publickGen, 0)
-	return addMod(pb, privateGen, ec.N)
+//DeriveKeyPair 根据私钥生成秘钥对
+func (*Secp256KeyPair) DeriveKeyPair(secret string) (jtCrypto.PrivateKey, error) {
+	priv, err := deriveKeyPair(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return priv, nil
 }
 
-//DeriveKeyPair 根据私钥生成秘钥对
-func (*Secp256KeyPair) DeriveKeyPair(secret string) (*PrivateKey, error) {
-	decodedBytes, err := jtEncode.Base58Decode(secret, jtEncode.JingTumAlphabet)
-	if err != nil || decodedBytes[0] != jtConst.SeedPrefix || len(decodedBytes) < 5 {
-		err = fmt.Errorf("invalid input size")
+//deriveKeyPair 私钥的账户为账户族中序号 0 的账户
+func deriveKeyPair(secret string) (*PrivateKey, error) {
+	return DeriveAccount(secret, 0)
+}
+
+//DeriveAccount 派生私钥账户族中序号为 index 的账户私钥，序号 0 即 DeriveKeyPair 的私钥
+func DeriveAccount(secret string, index uint32) (*PrivateKey, error) {
+	family, err := NewFamily(secret)
+	if err != nil {
 		return nil, err
 	}
-	var priv PrivateKey
-	entropy := decodedBytes[1 : len(decodedBytes)-4]
-	priv.D = derivePrivateKey(entropy)
-	Q := ec.ScalarBaseMult(priv.D)
-	priv.X = Q.X
-	priv.Y = Q.Y
-	return &priv, nil
+
+	return family.PrivateKey(index)
 }
 
 //GenerateSeed 生成私钥
@@ -127,6 +126,53 @@ func (pub *PublicKey) ToBytes() (b []byte) {
 	return append([]byte{0x03}, paddedx...)
 }
 
+//ParsePublicKey 解析 33 字节压缩公钥（02/03 + X）或 65 字节非压缩公钥（04 + X + Y），公钥须在曲线上
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	if len(b) == 33 {
+		P, err := ec.Decompress(b)
+		if err != nil {
+			return nil, err
+		}
+
+		return &PublicKey{P}, nil
+	}
+
+	if len(b) != 65 || b[0] != 0x04 {
+		return nil, fmt.Errorf("invalid public key size %d", len(b))
+	}
+
+	pub := &PublicKey{Point{X: new(big.Int).SetBytes(b[1:33]), Y: new(big.Int).SetBytes(b[33:])}}
+	if !pub.IsValid() {
+		return nil, fmt.Errorf("point is not on curve")
+	}
+
+	return pub, nil
+}
+
+//IsValidPublicKey 是否为合法的 33 字节压缩公钥或 65 字节非压缩公钥
+func IsValidPublicKey(b []byte) bool {
+	_, err := ParsePublicKey(b)
+	return err == nil
+}
+
+//IsValid 公钥坐标小于 P 且在曲线上
+func (pub *PublicKey) IsValid() bool {
+	if pub.X == nil || pub.Y == nil || pub.X.Cmp(ec.P) >= 0 || pub.Y.Cmp(ec.P) >= 0 {
+		return false
+	}
+
+	return ec.IsOnCurve(pub.Point)
+}
+
+//ToUncompressedBytes 65 字节非压缩公钥 04 + X + Y
+func (pub *PublicKey) ToUncompressedBytes() []byte {
+	b := make([]byte, 65)
+	b[0] = 0x04
+	pub.X.FillBytes(b[1:33])
+	pub.Y.FillBytes(b[33:])
+	return b
+}
+
 //ToBytes 私钥转成32字节
 func (priv *PrivateKey) ToBytes() (b []byte) {
 	d := priv.D.Bytes()
@@ -137,6 +183,54 @@ func (priv *PrivateKey) ToBytes() (b []byte) {
 	return paddedD
 }
 
+//KeyType 签名算法类型
+func (priv *PrivateKey) KeyType() jtCrypto.KeyType {
+	return jtCrypto.Secp256k1
+}
+
+//PublicKeyBytes 33字节压缩公钥
+func (priv *PrivateKey) PublicKeyBytes() []byte {
+	return priv.PublicKey.ToBytes()
+}
+
+//Sign 对签名数据的 sha512 half 哈希签名，返回 DER 编码的签名
+func (priv *PrivateKey) Sign(message []byte) ([]byte, error) {
+	sh512 := jtUtils.NewSha512()
+	sh512.Add(message)
+
+	return priv.SignHash(sh512.Finish256())
+}
+
+//SignHash 对 32 字节哈希签名，返回 DER 编码的签名。
+//随机数 k 按 RFC6979 由私钥和哈希确定，相同输入总是得到相同签名；签名为 low-S 的规范签名
+func (priv *PrivateKey) SignHash(hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("invalid hash size %d", len(hash))
+	}
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: btcec.S256(),
+			X:     priv.X,
+			Y:     priv.Y,
+		},
+		D: priv.D,
+	}
+
+	//btcec 按 RFC6979 生成 k，Serialize 时把 S 转换成 low-S
+	signature, err := (*btcec.PrivateKey)(key).Sign(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	der := signature.Serialize()
+	if !IsCanonicalSignature(der, true) {
+		return nil, fmt.Errorf("signature is not fully canonical")
+	}
+
+	return der, nil
+}
+
 //BytesToHex BytesToHex
 func (pub *PublicKey) BytesToHex() string {
 	return strings.ToUpper(hex.EncodeToString(pub.ToBytes()))
@@ -204,8 +298,7 @@ func scalarMultiple(bytes []byte) *big.Int {
 //PrivKeyFromBytes PrivKeyFromBytes
 func PrivKeyFromBytes(curve elliptic.Curve, secret string) (*btcec.PrivateKey,
 	*btcec.PublicKey) {
-	keyPair := &Secp256KeyPair{}
-	pri, _ := keyPair.DeriveKeyPair(secret)
+	pri, _ := deriveKeyPair(secret)
 	priv := &ecdsa.PrivateKey{
 		PublicKey: ecdsa.PublicKey{
 			Curve: curve,
@@ -1,5 +1,89 @@
 package secp256k1
 
+import (
+	"math/big"
+
+	jtUtils "jingtumlib/utils"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+//Verify 用 33 字节压缩公钥验证签名数据 sha512 half 哈希的 DER 签名，签名须为规范签名（见 IsCanonicalSignature）。
+//fullyCanonical 为 true（交易设置了 FullyCanonicalSig 标志）时还要求 low-S
+func Verify(pubKey []byte, message []byte, signature []byte, fullyCanonical bool) bool {
+	if !IsCanonicalSignature(signature, fullyCanonical) {
+		return false
+	}
+
+	pub, err := btcec.ParsePubKey(pubKey, btcec.S256())
+	if err != nil {
+		return false
+	}
+
+	sig, err := btcec.ParseDERSignature(signature, btcec.S256())
+	if err != nil {
+		return false
+	}
+
+	sh512 := jtUtils.NewSha512()
+	sh512.Add(message)
+
+	return sig.Verify(sh512.Finish256(), pub)
+}
+
+//IsCanonicalSignature DER 签名是否规范：严格的 DER 编码（无多余的前导 0，R、S 非负），且 0 < R、S < N。
+//fullyCanonical 为 true 时还要求 S <= N/2（low-S），同一签名不能再变换成另一个有效签名
+func IsCanonicalSignature(signature []byte, fullyCanonical bool) bool {
+	size := len(signature)
+	if size < 8 || size > 72 || signature[0] != 0x30 || int(signature[1]) != size-2 {
+		return false
+	}
+
+	r, rest, ok := parseDERInteger(signature[2:])
+	if !ok {
+		return false
+	}
+
+	sig, rest, ok := parseDERInteger(rest)
+	if !ok || len(rest) != 0 {
+		return false
+	}
+
+	R, S := new(big.Int).SetBytes(r), new(big.Int).SetBytes(sig)
+	if R.Sign() == 0 || S.Sign() == 0 || R.Cmp(ec.N) >= 0 || S.Cmp(ec.N) >= 0 {
+		return false
+	}
+
+	if fullyCanonical && S.Cmp(new(big.Int).Rsh(ec.N, 1)) > 0 {
+		return false
+	}
+
+	return true
+}
+
+//parseDERInteger 解析 DER 整数（0x02 长度 数据），数据为 1 至 33 字节，不能为负数或有多余的前导 0
+func parseDERInteger(data []byte) ([]byte, []byte, bool) {
+	if len(data) < 3 || data[0] != 0x02 {
+		return nil, nil, false
+	}
+
+	size := int(data[1])
+	if size < 1 || size > 33 || len(data) < 2+size {
+		return nil, nil, false
+	}
+
+	value := data[2 : 2+size]
+	if value[0]&0x80 != 0 {
+		return nil, nil, false
+	}
+
+	if size > 1 && value[0] == 0 && value[1]&0x80 == 0 {
+		return nil, nil, false
+	}
+
+	return value, data[2+size:], true
+}
+
 // func PrivKeyFromBytes(curve elliptic.Curve, secret string) (*btcec.PrivateKey,
 // 	*btcec.PublicKey) {
 // 	keyPair := &Secp256KeyPair{}
@@ -65,6 +65,26 @@ func NewAlphabet(alphabet string) *Alphabet {
 	return ret
 }
 
+/**
+ *  判断字符是否属于字母表。
+ *  params:
+ *      ch:待判断字符
+ *  return:
+ *      bool
+ */
+func (alphabet *Alphabet) Contains(ch rune) bool {
+	if ch >= 0 && ch < 256 {
+		return alphabet.decodeTable[ch] != -1
+	}
+
+	for i := 0; i < len(alphabet.unicodeDecodeTable); i += 2 {
+		if alphabet.unicodeDecodeTable[i] == ch {
+			return true
+		}
+	}
+	return false
+}
+
 /**
  *  以传入的字母表对输入值进行base58编码。
  *  params:
@@ -19,3 +19,17 @@ import (
 func Test_base58Encode(t *testing.T) {
 	t.Log(Base58Encode([]byte("ddddd"), JingTumAlphabet))
 }
+
+func Test_alphabetContains(t *testing.T) {
+	for _, ch := range "jpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65rkm8oFqi1tuvAxyz" {
+		if !JingTumAlphabet.Contains(ch) {
+			t.Fatalf("%c should be in alphabet", ch)
+		}
+	}
+
+	for _, ch := range "0OIl+/井" {
+		if JingTumAlphabet.Contains(ch) {
+			t.Fatalf("%c should not be in alphabet", ch)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/**
+ * 钱包族，同一个 secp256k1 私钥按序号派生多个账户，如为每个客户分配充值地址，只需备份一个私钥。
+ *
+ * @FileName: family.go
+ */
+package jingtumlib
+
+import (
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/crypto/secp256k1"
+)
+
+//WalletFamily 钱包族。序号 0 的账户即私钥本身的钱包（FromSecret），其他序号的账户由私钥和序号派生。
+//只读钱包族只有公开生成器，可以派生地址和公钥，不能签名
+type WalletFamily struct {
+	secret string
+	family *secp256k1.Family
+}
+
+//NewWalletFamily 根据 secp256k1 私钥创建钱包族，ed25519 私钥没有账户族
+func NewWalletFamily(secret string) (*WalletFamily, error) {
+	if secret == "" {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	if crypto.SecretKeyType(secret) != crypto.Secp256k1 {
+		return nil, constant.ERR_FAMILY_UNSUPPORTED_KEY
+	}
+
+	family, err := secp256k1.NewFamily(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletFamily{secret: secret, family: family}, nil
+}
+
+//NewWatchOnlyFamily 根据公开生成器（PublicGenerator）创建只读钱包族，如监控充值地址的服务不持有私钥
+func NewWatchOnlyFamily(generator string) (*WalletFamily, error) {
+	if generator == "" {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	family, err := secp256k1.NewPublicFamily(generator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletFamily{family: family}, nil
+}
+
+//DeriveAccount 派生私钥钱包族中序号为 index 的钱包，序号 0 即 FromSecret(secret)
+func DeriveAccount(secret string, index uint32) (*Wallet, error) {
+	family, err := NewWalletFamily(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return family.Wallet(index)
+}
+
+//Wallet 派生序号为 index 的钱包。序号大于 0 的钱包没有私钥字符串，签名时用 Transaction.SetSigner(wallet)，
+//备份时备份钱包族私钥和序号
+func (family *WalletFamily) Wallet(index uint32) (*Wallet, error) {
+	if family.IsWatchOnly() {
+		return nil, constant.ERR_FAMILY_WATCH_ONLY
+	}
+
+	priv, err := family.family.PrivateKey(index)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet := &Wallet{priv: priv}
+	if index == 0 {
+		wallet.secret = family.secret
+	}
+
+	return wallet, nil
+}
+
+//Address 派生序号为 index 的钱包地址，只读钱包族也可以派生
+func (family *WalletFamily) Address(index uint32) string {
+	return family.family.PublicKey(index).ToAddress()
+}
+
+//PublicKey 派生序号为 index 的 16 进制公钥，只读钱包族也可以派生
+func (family *WalletFamily) PublicKey(index uint32) string {
+	return family.family.PublicKey(index).BytesToHex()
+}
+
+//PublicGenerator 公开生成器，用 NewWatchOnlyFamily 创建只读钱包族
+func (family *WalletFamily) PublicGenerator() string {
+	return family.family.PublicGenerator()
+}
+
+//IsWatchOnly 是否为只读钱包族
+func (family *WalletFamily) IsWatchOnly() bool {
+	return family.family.IsWatchOnly()
+}
@@ -0,0 +1,100 @@
+/**
+ * 钱包族测试类
+ *
+ * @FileName: family_test.go
+ */
+package jingtumlib
+
+import (
+	"testing"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto/secp256k1"
+)
+
+//Test_WalletFamily 同一私钥派生的钱包与只读钱包族派生的地址一致
+func Test_WalletFamily(t *testing.T) {
+	secret := "ssc5eiFivvU2otV6bSYmJeZrAsQK3"
+	addresses := []string{
+		"jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"jRfmjR4o9maVy8CYRUnN6p9Ms65r2YEz4",
+		"jEbrcvoAEWAY67zaEtVfJXnqnx7DdGcfuL",
+	}
+
+	family, err := NewWalletFamily(secret)
+	if err != nil {
+		t.Fatalf("NewWalletFamily fail : %s", err.Error())
+	}
+
+	watch, err := NewWatchOnlyFamily(family.PublicGenerator())
+	if err != nil || !watch.IsWatchOnly() || family.IsWatchOnly() {
+		t.Fatalf("NewWatchOnlyFamily fail : %v", err)
+	}
+
+	for i, address := range addresses {
+		if watch.Address(uint32(i)) != address || family.Address(uint32(i)) != address {
+			t.Fatalf("Address(%d) = %s, expect %s", i, watch.Address(uint32(i)), address)
+		}
+
+		wallet, err := DeriveAccount(secret, uint32(i))
+		if err != nil || wallet.GetAddress() != address || wallet.GetPublicKey() != watch.PublicKey(uint32(i)) {
+			t.Fatalf("DeriveAccount(%d) = %s, err %v", i, wallet.GetAddress(), err)
+		}
+	}
+
+	//序号 0 即私钥本身的钱包
+	first, _ := family.Wallet(0)
+	if first.GetSecret() != secret {
+		t.Fatalf("Secret of index 0 is %s", first.GetSecret())
+	}
+
+	//派生的钱包可以签名，但没有私钥字符串，不能导出
+	derived, _ := family.Wallet(1)
+	signature, err := derived.SignMessage([]byte("deposit"))
+	if err != nil {
+		t.Fatalf("SignMessage fail : %s", err.Error())
+	}
+	if err := VerifyMessage(addresses[1], []byte("deposit"), signature); err != nil {
+		t.Fatalf("VerifyMessage fail : %s", err.Error())
+	}
+
+	if derived.GetSecret() != "" {
+		t.Fatalf("Derived wallet secret %s", derived.GetSecret())
+	}
+	if _, err := derived.ExportKeystore("password"); err != constant.ERR_WALLET_NO_SECRET {
+		t.Fatalf("ExportKeystore err %v", err)
+	}
+	if _, err := derived.GetMnemonic(); err != constant.ERR_WALLET_NO_SECRET {
+		t.Fatalf("GetMnemonic err %v", err)
+	}
+
+	if _, err := watch.Wallet(1); err != constant.ERR_FAMILY_WATCH_ONLY {
+		t.Fatalf("Watch-only Wallet err %v", err)
+	}
+
+	if _, err := NewWalletFamily("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"); err != constant.ERR_FAMILY_UNSUPPORTED_KEY {
+		t.Fatalf("Ed25519 family err %v", err)
+	}
+
+	if _, err := NewWatchOnlyFamily(secret); err == nil {
+		t.Fatalf("Invalid generator should fail")
+	}
+}
+
+//Test_FamilyInvalidSecret 空的、截断的、校验码错误的私钥返回错误，不会 panic
+func Test_FamilyInvalidSecret(t *testing.T) {
+	for _, secret := range []string{"", "s", "ssc5eiFiv", "ssc5eiFivvU2otV6bSYmJeZrAsQK0"} {
+		if _, err := secp256k1.NewFamily(secret); err == nil {
+			t.Fatalf("NewFamily(%q) should fail", secret)
+		}
+		if _, err := secp256k1.DeriveAccount(secret, 1); err == nil {
+			t.Fatalf("secp256k1.DeriveAccount(%q) should fail", secret)
+		}
+		if _, err := DeriveAccount(secret, 1); err == nil {
+			t.Fatalf("DeriveAccount(%q) should fail", secret)
+		}
+		if _, err := secp256k1.NewPublicFamily(secret); err == nil {
+			t.Fatalf("NewPublicFamily(%q) should fail", secret)
+		}
+	}
+}
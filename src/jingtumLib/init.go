@@ -30,7 +30,7 @@ func Init() error {
 		return err
 	}
 	constant.CFGCurrency = JTConfig.Read("Config", "currency")
-	return nil
+	return SetKeyCacheSize(JTConfig.ReadInt("Config", "key_cache_size", DefaultKeyCacheSize))
 }
 
 //Exits 退出
@@ -0,0 +1,306 @@
+/**
+ * 钱包文件（keystore），用口令加密保存钱包私钥。
+ *
+ * @FileName: keystore.go
+ */
+package jingtumlib
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//KeystoreVersion 钱包文件格式版本
+const KeystoreVersion = 1
+
+//KeystoreIterations 导出钱包文件时 PBKDF2 的迭代次数
+const KeystoreIterations = 262144
+
+const (
+	keystoreKDF     = "pbkdf2"
+	keystorePRF     = "hmac-sha256"
+	keystoreCipher  = "aes-256-gcm"
+	keystoreKeyLen  = 32
+	keystoreSaltLen = 32
+	//keystoreMaxIterations 导入时迭代次数上限，防止恶意文件耗尽 CPU
+	keystoreMaxIterations = 1 << 24
+)
+
+//Keystore 钱包文件，私钥用 PBKDF2 从口令派生的密钥以 AES-256-GCM 加密，地址作为附加认证数据
+type Keystore struct {
+	Version int            `json:"version"`
+	Address string         `json:"address"`
+	KeyType crypto.KeyType `json:"key_type"`
+	Crypto  KeystoreCrypto `json:"crypto"`
+}
+
+//KeystoreCrypto 钱包文件的加密参数，二进制数据为 16 进制字符串
+type KeystoreCrypto struct {
+	Cipher     string           `json:"cipher"`
+	CipherText string           `json:"ciphertext"`
+	Nonce      string           `json:"nonce"`
+	KDF        string           `json:"kdf"`
+	KDFParams  KeystoreKDFParam `json:"kdfparams"`
+}
+
+//KeystoreKDFParam PBKDF2 参数
+type KeystoreKDFParam struct {
+	PRF        string `json:"prf"`
+	Iterations int    `json:"c"`
+	KeyLen     int    `json:"dklen"`
+	Salt       string `json:"salt"`
+}
+
+//ExportKeystore 用口令加密钱包私钥，返回 JSON 格式的钱包文件
+func (wallet *Wallet) ExportKeystore(password string) ([]byte, error) {
+	if password == "" {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	if wallet.secret == "" {
+		return nil, constant.ERR_WALLET_NO_SECRET
+	}
+
+	salt := make([]byte, keystoreSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	params := KeystoreKDFParam{PRF: keystorePRF, Iterations: KeystoreIterations, KeyLen: keystoreKeyLen, Salt: hex.EncodeToString(salt)}
+	aead, err := keystoreAEAD(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	address := wallet.GetAddress()
+	ks := Keystore{
+		Version: KeystoreVersion,
+		Address: address,
+		KeyType: wallet.GetKeyType(),
+		Crypto: KeystoreCrypto{
+			Cipher:     keystoreCipher,
+			CipherText: hex.EncodeToString(aead.Seal(nil, nonce, []byte(wallet.secret), []byte(address))),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        keystoreKDF,
+			KDFParams:  params,
+		},
+	}
+
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+//ImportKeystore 用口令解密钱包文件，返回钱包。口令错误或文件被篡改时返回 constant.ERR_KEYSTORE_PASSWORD
+func ImportKeystore(data []byte, password string) (*Wallet, error) {
+	if password == "" {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	ks, err := parseKeystore(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if ks.Crypto.Cipher != keystoreCipher || ks.Crypto.KDF != keystoreKDF {
+		return nil, fmt.Errorf("Unsupported keystore cipher %s or kdf %s", ks.Crypto.Cipher, ks.Crypto.KDF)
+	}
+
+	params := ks.Crypto.KDFParams
+	if params.PRF != keystorePRF || params.KeyLen != keystoreKeyLen || params.Iterations <= 0 || params.Iterations > keystoreMaxIterations {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	aead, err := keystoreAEAD(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(ks.Crypto.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	secret, err := aead.Open(nil, nonce, cipherText, []byte(ks.Address))
+	if err != nil {
+		return nil, constant.ERR_KEYSTORE_PASSWORD
+	}
+
+	wallet, err := FromSecret(string(secret))
+	for i := range secret {
+		secret[i] = 0
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet.GetAddress() != ks.Address {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	return wallet, nil
+}
+
+//parseKeystore 解析钱包文件并检查版本和地址，不解密
+func parseKeystore(data []byte) (*Keystore, error) {
+	ks := new(Keystore)
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	if ks.Version != KeystoreVersion {
+		return nil, fmt.Errorf("Unsupported keystore version %d", ks.Version)
+	}
+
+	if !IsValidAddress(ks.Address) {
+		return nil, constant.ERR_KEYSTORE_INVALID
+	}
+
+	return ks, nil
+}
+
+//keystoreAEAD 用 PBKDF2 从口令派生 AES-256-GCM 密钥
+func keystoreAEAD(password string, salt []byte, params KeystoreKDFParam) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(password), salt, params.Iterations, params.KeyLen, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+//KeystoreDir 以目录保存钱包文件，每个钱包一个文件，文件名为 <地址>.json
+type KeystoreDir struct {
+	dir string
+}
+
+//NewKeystoreDir 打开钱包文件目录，目录不存在时以 0700 权限创建
+func NewKeystoreDir(dir string) (*KeystoreDir, error) {
+	if dir == "" {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &KeystoreDir{dir: dir}, nil
+}
+
+//Addresses 列出目录中钱包文件的地址，只读取文件中的 address，不需要口令。无法解析的文件被忽略
+func (ksd *KeystoreDir) Addresses() ([]string, error) {
+	files, err := ioutil.ReadDir(ksd.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(ksd.dir, file.Name()))
+		if err != nil {
+			continue
+		}
+
+		if ks, err := parseKeystore(data); err == nil && file.Name() == ks.Address+".json" {
+			addresses = append(addresses, ks.Address)
+		}
+	}
+
+	return addresses, nil
+}
+
+//Store 用口令加密钱包并保存到目录，已存在的同一地址的文件被替换
+func (ksd *KeystoreDir) Store(wallet *Wallet, password string) error {
+	if wallet == nil {
+		return constant.ERR_EMPTY_PARAM
+	}
+
+	data, err := wallet.ExportKeystore(password)
+	if err != nil {
+		return err
+	}
+
+	//先写临时文件再改名，避免写入中断留下不完整的钱包文件
+	tmp, err := ioutil.TempFile(ksd.dir, ".keystore-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), ksd.path(wallet.GetAddress()))
+}
+
+//Load 用口令解密目录中指定地址的钱包文件
+func (ksd *KeystoreDir) Load(address string, password string) (*Wallet, error) {
+	if !IsValidAddress(address) {
+		return nil, constant.ERR_INVALID_PARAM
+	}
+
+	data, err := ioutil.ReadFile(ksd.path(address))
+	if os.IsNotExist(err) {
+		return nil, constant.ERR_KEYSTORE_NOT_FOUND
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ImportKeystore(data, password)
+}
+
+//Delete 删除目录中指定地址的钱包文件
+func (ksd *KeystoreDir) Delete(address string) error {
+	if !IsValidAddress(address) {
+		return constant.ERR_INVALID_PARAM
+	}
+
+	err := os.Remove(ksd.path(address))
+	if os.IsNotExist(err) {
+		return constant.ERR_KEYSTORE_NOT_FOUND
+	}
+
+	return err
+}
+
+func (ksd *KeystoreDir) path(address string) string {
+	return filepath.Join(ksd.dir, address+".json")
+}
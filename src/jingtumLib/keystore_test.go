@@ -0,0 +1,132 @@
+/**
+ * 钱包文件测试类
+ *
+ * @FileName: keystore_test.go
+ */
+package jingtumlib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"jingtumlib/constant"
+)
+
+//Test_Keystore 钱包文件导出导入
+func Test_Keystore(t *testing.T) {
+	for _, secret := range []string{"ssc5eiFivvU2otV6bSYmJeZrAsQK3", "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"} {
+		wallet, _ := FromSecret(secret)
+		data, err := wallet.ExportKeystore("password")
+		if err != nil {
+			t.Fatalf("ExportKeystore fail : %s", err.Error())
+		}
+
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("Keystore contains the secret : %s", data)
+		}
+
+		imported, err := ImportKeystore(data, "password")
+		if err != nil {
+			t.Fatalf("ImportKeystore fail : %s", err.Error())
+		}
+
+		if imported.GetSecret() != secret || imported.GetKeyType() != wallet.GetKeyType() {
+			t.Fatalf("Imported wallet %s, %s", imported.GetAddress(), imported.GetKeyType())
+		}
+
+		if _, err := ImportKeystore(data, "wrong"); err != constant.ERR_KEYSTORE_PASSWORD {
+			t.Fatalf("Wrong password err %v", err)
+		}
+	}
+
+	wallet, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	data, _ := wallet.ExportKeystore("password")
+
+	var ks Keystore
+	json.Unmarshal(data, &ks)
+	if ks.Version != KeystoreVersion || ks.Address != wallet.GetAddress() || ks.Crypto.KDFParams.Iterations != KeystoreIterations {
+		t.Fatalf("Keystore %s", data)
+	}
+
+	//地址是附加认证数据，篡改后无法解密
+	tampered := ks
+	tampered.Address = "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"
+	data, _ = json.Marshal(tampered)
+	if _, err := ImportKeystore(data, "password"); err != constant.ERR_KEYSTORE_PASSWORD {
+		t.Fatalf("Tampered address err %v", err)
+	}
+
+	tampered = ks
+	tampered.Version = 2
+	data, _ = json.Marshal(tampered)
+	if _, err := ImportKeystore(data, "password"); err == nil {
+		t.Fatalf("Unsupported version should fail")
+	}
+
+	tampered = ks
+	tampered.Crypto.KDFParams.Iterations = 1 << 30
+	data, _ = json.Marshal(tampered)
+	if _, err := ImportKeystore(data, "password"); err != constant.ERR_KEYSTORE_INVALID {
+		t.Fatalf("Too many iterations err %v", err)
+	}
+
+	if _, err := wallet.ExportKeystore(""); err != constant.ERR_EMPTY_PARAM {
+		t.Fatalf("Empty password err %v", err)
+	}
+}
+
+//Test_KeystoreDir 钱包文件目录
+func Test_KeystoreDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatalf("TempDir fail : %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewKeystoreDir(filepath.Join(dir, "wallets"))
+	if err != nil {
+		t.Fatalf("NewKeystoreDir fail : %s", err.Error())
+	}
+
+	wallet1, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	wallet2, _ := FromSecret("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j")
+	for _, wallet := range []*Wallet{wallet1, wallet2} {
+		if err := store.Store(wallet, "password"); err != nil {
+			t.Fatalf("Store fail : %s", err.Error())
+		}
+	}
+
+	info, _ := os.Stat(filepath.Join(dir, "wallets", wallet1.GetAddress()+".json"))
+	if info == nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Keystore file mode %v", info)
+	}
+
+	//无关文件被忽略
+	ioutil.WriteFile(filepath.Join(dir, "wallets", "readme.json"), []byte("{}"), 0600)
+
+	addresses, err := store.Addresses()
+	if err != nil || len(addresses) != 2 {
+		t.Fatalf("Addresses %v, err %v", addresses, err)
+	}
+
+	wallet, err := store.Load(wallet2.GetAddress(), "password")
+	if err != nil || wallet.GetSecret() != wallet2.GetSecret() {
+		t.Fatalf("Load fail : %v", err)
+	}
+
+	if err := store.Delete(wallet1.GetAddress()); err != nil {
+		t.Fatalf("Delete fail : %s", err.Error())
+	}
+
+	if _, err := store.Load(wallet1.GetAddress(), "password"); err != constant.ERR_KEYSTORE_NOT_FOUND {
+		t.Fatalf("Load deleted keystore err %v", err)
+	}
+
+	if addresses, _ := store.Addresses(); len(addresses) != 1 || addresses[0] != wallet2.GetAddress() {
+		t.Fatalf("Addresses after delete %v", addresses)
+	}
+}
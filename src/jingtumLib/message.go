@@ -0,0 +1,160 @@
+/**
+ * 消息签名和登录验证，用钱包签名任意数据，不涉及交易。
+ *
+ * @FileName: message.go
+ */
+package jingtumlib
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+)
+
+//messageSigningData 消息的签名数据：MessageSignPrefix + 消息长度 + 消息
+func messageSigningData(message []byte) []byte {
+	data := make([]byte, 0, len(constant.MessageSignPrefix)+20+len(message))
+	data = append(data, constant.MessageSignPrefix...)
+	data = strconv.AppendInt(data, int64(len(message)), 10)
+	return append(data, message...)
+}
+
+//signMessage 签名者对消息签名，签名为 16 进制的 33 字节公钥 + 签名
+func signMessage(signer Signer, message []byte) (string, error) {
+	signature, err := signer.Sign(messageSigningData(message))
+	if err != nil {
+		return "", err
+	}
+
+	return signer.GetPublicKey() + signature, nil
+}
+
+//SignMessage 对任意消息签名，签名数据带有 MessageSignPrefix 前缀，不能当作交易签名。
+//返回 16 进制的 33 字节公钥 + 签名，由 VerifyMessage 根据地址验证
+func (wallet *Wallet) SignMessage(message []byte) (string, error) {
+	return signMessage(wallet, message)
+}
+
+//SignMessage 由签名服务对消息签名，同 Wallet.SignMessage
+func (signer *RemoteSigner) SignMessage(message []byte) (string, error) {
+	return signMessage(signer, message)
+}
+
+//VerifyMessage 验证 SignMessage 的签名：签名中的公钥须对应 address，且签名有效
+func VerifyMessage(address string, message []byte, signature string) error {
+	data, err := hex.DecodeString(signature)
+	if err != nil || len(data) <= 33 {
+		return constant.ERR_MESSAGE_INVALID_SIGNATURE
+	}
+
+	pubKey, sig := data[:33], data[33:]
+	if crypto.AddressFromPublicKey(pubKey) != address {
+		return constant.ERR_MESSAGE_INVALID_SIGNATURE
+	}
+
+	if !verifyKeySignature(pubKey, messageSigningData(message), sig, true) {
+		return constant.ERR_MESSAGE_INVALID_SIGNATURE
+	}
+
+	return nil
+}
+
+//LoginChallenge 登录挑战，服务端为声称拥有 Address 的用户生成，用户用钱包对 Message() 签名后由 Verify 验证。
+//Nonce 应只使用一次，服务端需要记录已验证的 Nonce 防止重放
+type LoginChallenge struct {
+	Domain    string
+	Address   string
+	Nonce     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+const (
+	loginStatement = " wants you to sign in with your Jingtum account:"
+	loginNonce     = "Nonce: "
+	loginIssuedAt  = "Issued At: "
+	loginExpiresAt = "Expiration Time: "
+)
+
+//NewLoginChallenge 生成登录挑战，domain 为服务的域名，ttl 为有效期
+func NewLoginChallenge(domain string, address string, ttl time.Duration) (*LoginChallenge, error) {
+	if domain == "" || strings.ContainsAny(domain, " \n") || ttl <= 0 {
+		return nil, constant.ERR_INVALID_PARAM
+	}
+
+	if !IsValidAddress(address) {
+		return nil, constant.ERR_INVALID_PARAM
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	return &LoginChallenge{Domain: domain, Address: address, Nonce: hex.EncodeToString(nonce), IssuedAt: now, ExpiresAt: now.Add(ttl)}, nil
+}
+
+//Message 用户签名的文本：
+//
+//	<Domain> wants you to sign in with your Jingtum account:
+//	<Address>
+//
+//	Nonce: <Nonce>
+//	Issued At: <RFC3339 时间>
+//	Expiration Time: <RFC3339 时间>
+func (challenge *LoginChallenge) Message() []byte {
+	lines := []string{
+		challenge.Domain + loginStatement,
+		challenge.Address,
+		"",
+		loginNonce + challenge.Nonce,
+		loginIssuedAt + challenge.IssuedAt.UTC().Format(time.RFC3339),
+		loginExpiresAt + challenge.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+//ParseLoginChallenge 解析 Message() 的文本，钱包签名前可以据此确认域名和地址
+func ParseLoginChallenge(message []byte) (*LoginChallenge, error) {
+	lines := strings.Split(string(message), "\n")
+	if len(lines) != 6 || !strings.HasSuffix(lines[0], loginStatement) || lines[2] != "" {
+		return nil, fmt.Errorf("Invalid login challenge")
+	}
+
+	challenge := &LoginChallenge{Domain: strings.TrimSuffix(lines[0], loginStatement), Address: lines[1]}
+	if !strings.HasPrefix(lines[3], loginNonce) || !strings.HasPrefix(lines[4], loginIssuedAt) || !strings.HasPrefix(lines[5], loginExpiresAt) {
+		return nil, fmt.Errorf("Invalid login challenge")
+	}
+	challenge.Nonce = strings.TrimPrefix(lines[3], loginNonce)
+
+	var err error
+	if challenge.IssuedAt, err = time.Parse(time.RFC3339, strings.TrimPrefix(lines[4], loginIssuedAt)); err != nil {
+		return nil, fmt.Errorf("Invalid login challenge issued time")
+	}
+	if challenge.ExpiresAt, err = time.Parse(time.RFC3339, strings.TrimPrefix(lines[5], loginExpiresAt)); err != nil {
+		return nil, fmt.Errorf("Invalid login challenge expiration time")
+	}
+
+	if !IsValidAddress(challenge.Address) || challenge.Nonce == "" {
+		return nil, fmt.Errorf("Invalid login challenge")
+	}
+
+	return challenge, nil
+}
+
+//Verify 验证用户对挑战的签名，挑战过期时返回 constant.ERR_LOGIN_CHALLENGE_EXPIRED
+func (challenge *LoginChallenge) Verify(signature string) error {
+	if time.Now().After(challenge.ExpiresAt) {
+		return constant.ERR_LOGIN_CHALLENGE_EXPIRED
+	}
+
+	return VerifyMessage(challenge.Address, challenge.Message(), signature)
+}
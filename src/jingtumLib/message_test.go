@@ -0,0 +1,111 @@
+/**
+ * 消息签名测试类
+ *
+ * @FileName: message_test.go
+ */
+package jingtumlib
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"jingtumlib/constant"
+)
+
+//Test_Message secp256k1 和 ed25519 钱包的消息签名与验证
+func Test_Message(t *testing.T) {
+	for _, secret := range []string{"ssc5eiFivvU2otV6bSYmJeZrAsQK3", "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"} {
+		wallet, _ := FromSecret(secret)
+		message := []byte("hello jingtum")
+
+		signature, err := wallet.SignMessage(message)
+		if err != nil {
+			t.Fatalf("SignMessage fail : %s", err.Error())
+		}
+
+		if !strings.HasPrefix(signature, wallet.GetPublicKey()) {
+			t.Fatalf("Signature %s does not start with public key", signature)
+		}
+
+		if err := VerifyMessage(wallet.GetAddress(), message, signature); err != nil {
+			t.Fatalf("VerifyMessage fail : %s", err.Error())
+		}
+
+		if err := VerifyMessage("jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", message, signature); err != constant.ERR_MESSAGE_INVALID_SIGNATURE {
+			t.Fatalf("Wrong address err %v", err)
+		}
+
+		if err := VerifyMessage(wallet.GetAddress(), []byte("hello jingtum!"), signature); err != constant.ERR_MESSAGE_INVALID_SIGNATURE {
+			t.Fatalf("Tampered message err %v", err)
+		}
+
+		for _, invalid := range []string{"", "zz", wallet.GetPublicKey(), signature[:len(signature)-2]} {
+			if err := VerifyMessage(wallet.GetAddress(), message, invalid); err != constant.ERR_MESSAGE_INVALID_SIGNATURE {
+				t.Fatalf("Invalid signature %s err %v", invalid, err)
+			}
+		}
+
+		//消息签名不能当作交易签名：对交易签名数据签名的消息，在交易签名数据上验证失败
+		txData := append([]byte{0x53, 0x54, 0x58, 0x00}, 0x12, 0x00, 0x00)
+		signature, _ = wallet.SignMessage(txData)
+		sig, _ := hex.DecodeString(signature[66:])
+		pubKey, _ := hex.DecodeString(wallet.GetPublicKey())
+		if verifyKeySignature(pubKey, txData, sig, true) {
+			t.Fatalf("Message signature is valid as transaction signature")
+		}
+	}
+}
+
+//Test_LoginChallenge 登录挑战的生成、解析、签名和过期
+func Test_LoginChallenge(t *testing.T) {
+	wallet, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+
+	challenge, err := NewLoginChallenge("example.com", wallet.GetAddress(), time.Minute)
+	if err != nil {
+		t.Fatalf("NewLoginChallenge fail : %s", err.Error())
+	}
+
+	other, _ := NewLoginChallenge("example.com", wallet.GetAddress(), time.Minute)
+	if challenge.Nonce == other.Nonce || len(challenge.Nonce) != 32 {
+		t.Fatalf("Nonce %s, %s", challenge.Nonce, other.Nonce)
+	}
+
+	//钱包端解析挑战，确认域名和地址后签名
+	parsed, err := ParseLoginChallenge(challenge.Message())
+	if err != nil {
+		t.Fatalf("ParseLoginChallenge fail : %s", err.Error())
+	}
+	if parsed.Domain != "example.com" || parsed.Address != wallet.GetAddress() || parsed.Nonce != challenge.Nonce ||
+		!parsed.IssuedAt.Equal(challenge.IssuedAt) || !parsed.ExpiresAt.Equal(challenge.ExpiresAt) {
+		t.Fatalf("Parsed challenge %+v, expect %+v", parsed, challenge)
+	}
+
+	signature, _ := wallet.SignMessage(parsed.Message())
+	if err := challenge.Verify(signature); err != nil {
+		t.Fatalf("Verify fail : %s", err.Error())
+	}
+
+	//其他挑战的签名无效
+	if err := other.Verify(signature); err != constant.ERR_MESSAGE_INVALID_SIGNATURE {
+		t.Fatalf("Other challenge err %v", err)
+	}
+
+	challenge.ExpiresAt = time.Now().Add(-time.Second)
+	if err := challenge.Verify(signature); err != constant.ERR_LOGIN_CHALLENGE_EXPIRED {
+		t.Fatalf("Expired challenge err %v", err)
+	}
+
+	if _, err := ParseLoginChallenge([]byte("example.com wants you to sign in")); err == nil {
+		t.Fatalf("Invalid challenge should fail")
+	}
+
+	if _, err := NewLoginChallenge("example.com", "jInvalid", time.Minute); err == nil {
+		t.Fatalf("Invalid address should fail")
+	}
+
+	if _, err := NewLoginChallenge("example.com\n", wallet.GetAddress(), time.Minute); err == nil {
+		t.Fatalf("Invalid domain should fail")
+	}
+}
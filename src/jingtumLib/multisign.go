@@ -0,0 +1,299 @@
+/**
+ * 多重签名。
+ *
+ * @FileName: multisign.go
+ */
+package jingtumlib
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/serializer"
+	"jingtumlib/utils"
+)
+
+//SignFor 以 signer（如 Wallet）的地址作为签名者对交易做多重签名，返回 TxnSignatures 中只有该签名者的交易 blob，
+//各签名者的 blob 由 CombineSignatures 或 remote.BuildMultiSignedTx 合并。
+//签名前需要设置 Sequence，多重签名交易的 Fee 通常为单签手续费 * (签名者数 + 1)。
+func (tx *Transaction) SignFor(signer Signer) (string, error) {
+	if signer == nil {
+		return "", constant.ERR_EMPTY_PARAM
+	}
+
+	if tx.checkTxError() {
+		return "", tx.GetTxJSON(constant.TxJSONErrorKey).(error)
+	}
+
+	if !tx.hasSequence() {
+		return "", fmt.Errorf("Sequence is required to sign transaction")
+	}
+
+	if tx.GetTxJSON("TransactionType") == "SignerListSet" {
+		return "", constant.ERR_TX_SERVER_SIGN_ONLY
+	}
+
+	txJSON, err := tx.multiSigningJSON()
+	if err != nil {
+		return "", err
+	}
+
+	address, err := signerAddress(signer)
+	if err != nil {
+		return "", err
+	}
+
+	message, err := multiSigningData(txJSON, address)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := signer.Sign(message)
+	if err != nil {
+		return "", err
+	}
+
+	entry := map[string]interface{}{"Account": address, "SigningPubKey": signer.GetPublicKey(), "TxnSignature": signature}
+	txJSON["TxnSignatures"] = []interface{}{map[string]interface{}{"Signer": entry}}
+	return encodeTxJSON(txJSON)
+}
+
+//CombineSignatures 合并 SignFor 得到的多个 blob：校验各 blob 是同一交易、各签名有效，
+//同一签名者只保留一个签名，签名者按账号（AccountID）升序排列后返回可提交的 blob
+func CombineSignatures(blobs ...string) (string, error) {
+	if len(blobs) == 0 {
+		return "", constant.ERR_EMPTY_PARAM
+	}
+
+	var combined map[string]interface{}
+	var unsigned string
+	signers := make(map[string]interface{})
+	for _, blob := range blobs {
+		txJSON, err := serializer.Decode(blob)
+		if err != nil {
+			return "", err
+		}
+
+		items, _ := txJSON["TxnSignatures"].([]interface{})
+		if len(items) == 0 {
+			return "", constant.ERR_TX_NOT_SIGNED
+		}
+		delete(txJSON, "TxnSignatures")
+
+		so, err := serializer.FromTxJSON(txJSON)
+		if err != nil {
+			return "", err
+		}
+		data := so.ToHex()
+		so.Release()
+
+		if combined == nil {
+			combined, unsigned = txJSON, data
+		} else if data != unsigned {
+			return "", fmt.Errorf("Multi-signed transactions are different")
+		}
+
+		//签名者可能用关联密钥签名，这里只验证签名，签名者是否有权签名由底层校验
+		for _, item := range items {
+			entry, _ := item.(map[string]interface{})
+			signer, _ := entry["Signer"].(map[string]interface{})
+			if _, err := verifySigner(txJSON, signer); err != nil {
+				return "", err
+			}
+			signers[signer["Account"].(string)] = item
+		}
+	}
+
+	combined["TxnSignatures"] = sortSigners(signers)
+	return encodeTxJSON(combined)
+}
+
+//multiSigningJSON 多重签名的 tx_json（SWT 金额为 drops），设置 FullyCanonicalSig 标志，SigningPubKey 为空，不含 TxnSignature 和 TxnSignatures。
+//标志和金额格式在副本上修改，同一交易多次 SignFor 结果相同，交易本身不变
+func (tx *Transaction) multiSigningJSON() (map[string]interface{}, error) {
+	var so *serializer.Serializer
+	var err error
+	if tx.txData != nil {
+		so, err = serializer.FromTx(tx.txData)
+	} else {
+		unsigned := &Transaction{txJSON: make(map[string]interface{}, len(tx.txJSON)), prepared: tx.prepared}
+		for k, v := range tx.txJSON {
+			if k != "SigningPubKey" && k != "TxnSignature" && k != "TxnSignatures" {
+				unsigned.txJSON[k] = v
+			}
+		}
+
+		//prepareTxJSON 会就地转换备注，未转换时复制备注
+		if memos, ok := unsigned.txJSON["Memos"].(*list.List); ok && !tx.prepared {
+			unsigned.txJSON["Memos"] = copyMemos(memos)
+		}
+
+		unsigned.setCanonicalFlag()
+		if err := prepareTxJSON(unsigned); err != nil {
+			return nil, err
+		}
+		so, err = serializer.FromJSON(unsigned.txJSON)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	txJSON, err := serializer.Decode(so.ToHex())
+	so.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	if tx.txData != nil {
+		delete(txJSON, "TxnSignature")
+		delete(txJSON, "TxnSignatures")
+		(&Transaction{txJSON: txJSON}).setCanonicalFlag()
+	}
+
+	txJSON["SigningPubKey"] = ""
+	return txJSON, nil
+}
+
+//copyMemos 复制备注列表
+func copyMemos(memos *list.List) *list.List {
+	copied := list.New()
+	for e := memos.Front(); e != nil; e = e.Next() {
+		if info, ok := e.Value.(*serializer.MemoInfo); ok && info.Memo != nil {
+			memo := *info.Memo
+			copied.PushBack(&serializer.MemoInfo{Memo: &memo})
+		} else {
+			copied.PushBack(e.Value)
+		}
+	}
+
+	return copied
+}
+
+//multiSigningData 签名者的签名数据：SMT 前缀 + 不含 TxnSignatures 的序列化交易 + 签名者 AccountID
+func multiSigningData(txJSON map[string]interface{}, account string) ([]byte, error) {
+	accountID, err := utils.DecodeAddress(account)
+	if err != nil {
+		return nil, err
+	}
+
+	unsigned := make(map[string]interface{}, len(txJSON))
+	for k, v := range txJSON {
+		if k != "TxnSignatures" && k != "TxnSignature" {
+			unsigned[k] = v
+		}
+	}
+
+	so, err := serializer.FromTxJSON(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	message := append(so.SigningData(constant.HashPrefixTxMultiSign), accountID...)
+	so.Release()
+
+	return message, nil
+}
+
+//verifySigner 验证 TxnSignatures 中一个签名者的签名，返回签名公钥对应的地址
+func verifySigner(txJSON map[string]interface{}, signer map[string]interface{}) (string, error) {
+	account, _ := signer["Account"].(string)
+	pubHex, _ := signer["SigningPubKey"].(string)
+	sigHex, _ := signer["TxnSignature"].(string)
+	if account == "" || pubHex == "" || sigHex == "" {
+		return "", fmt.Errorf("Invalid signer %v", signer)
+	}
+
+	pubKey, err := hex.DecodeString(pubHex)
+	if err != nil || len(pubKey) != 33 {
+		return "", fmt.Errorf("Invalid SigningPubKey %s", pubHex)
+	}
+
+	signature, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return "", fmt.Errorf("Invalid TxnSignature %s", sigHex)
+	}
+
+	message, err := multiSigningData(txJSON, account)
+	if err != nil {
+		return "", err
+	}
+
+	if !verifyKeySignature(pubKey, message, signature, isFullyCanonical(txJSON)) {
+		return "", constant.ERR_TX_INVALID_SIGNATURE
+	}
+
+	return crypto.AddressFromPublicKey(pubKey), nil
+}
+
+//verifySigners 验证多重签名交易的每个签名者，签名公钥须对应签名者账号或 regularKeys 中的地址。
+//签名者是否在账号的多重签名列表中、权重是否达到门限由底层校验
+func verifySigners(txJSON map[string]interface{}, regularKeys []string) error {
+	items, _ := txJSON["TxnSignatures"].([]interface{})
+	if len(items) == 0 {
+		return constant.ERR_TX_NOT_SIGNED
+	}
+
+	for _, item := range items {
+		entry, _ := item.(map[string]interface{})
+		signer, _ := entry["Signer"].(map[string]interface{})
+		address, err := verifySigner(txJSON, signer)
+		if err != nil {
+			return err
+		}
+
+		if address != signer["Account"] && !containsString(regularKeys, address) {
+			return constant.ERR_TX_SIGNER_NOT_AUTHORIZED
+		}
+	}
+
+	return nil
+}
+
+//sortSigners 签名者按 AccountID 升序排列
+func sortSigners(signers map[string]interface{}) []interface{} {
+	accounts := make([]string, 0, len(signers))
+	ids := make(map[string][]byte, len(signers))
+	for account := range signers {
+		accounts = append(accounts, account)
+		ids[account], _ = utils.DecodeAddress(account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return bytes.Compare(ids[accounts[i]], ids[accounts[j]]) < 0
+	})
+
+	items := make([]interface{}, 0, len(accounts))
+	for _, account := range accounts {
+		items = append(items, signers[account])
+	}
+
+	return items
+}
+
+//encodeTxJSON tx_json 序列化成大写 16 进制的 blob
+func encodeTxJSON(txJSON map[string]interface{}) (string, error) {
+	so, err := serializer.FromTxJSON(txJSON)
+	if err != nil {
+		return "", err
+	}
+
+	blob := strings.ToUpper(so.ToHex())
+	so.Release()
+	return blob, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
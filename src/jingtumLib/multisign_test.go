@@ -0,0 +1,225 @@
+/**
+ * 多重签名测试类
+ *
+ * @FileName: multisign_test.go
+ */
+package jingtumlib
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"testing"
+
+	"jingtumlib/constant"
+	"jingtumlib/serializer"
+	"jingtumlib/utils"
+)
+
+//Test_BuildSignerSet 设置多重签名列表，无需连接底层
+func Test_BuildSignerSet(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	account := "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72"
+	lists := []SignerEntry{{Account: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", SignerWeight: 2}, {Account: "jLUEXYuLiQptky37CqLcm9USQpPiz5jkpD", SignerWeight: 1}}
+	tx, err := remote.BuildAccountSetTx(map[string]interface{}{"type": "signer", "account": account, "threshold": uint32(3), "lists": lists})
+	if err != nil {
+		t.Fatalf("BuildAccountSetTx fail : %s", err.Error())
+	}
+	tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	tx.AddTxJSON("Sequence", uint32(60))
+
+	entries, _ := tx.GetTxJSON("SignerEntries").([]interface{})
+	if tx.GetTransactionType() != "SignerListSet" || tx.GetTxJSON("SignerQuorum") != uint32(3) || len(entries) != 2 {
+		t.Fatalf("SignerListSet %v", tx.txJSON)
+	}
+
+	entry := entries[0].(map[string]interface{})["SignerEntry"].(map[string]interface{})
+	if entry["Account"] != lists[0].Account || entry["SignerWeight"] != uint16(2) {
+		t.Fatalf("SignerEntry %v", entry)
+	}
+
+	//字段编码未经底层确认，只能由底层签名
+	if _, err := signing(tx); err != constant.ERR_TX_SERVER_SIGN_ONLY {
+		t.Fatalf("Local signing SignerListSet err %v", err)
+	}
+
+	wallet, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	if _, err := tx.SignFor(wallet); err != constant.ERR_TX_SERVER_SIGN_ONLY {
+		t.Fatalf("SignFor SignerListSet err %v", err)
+	}
+
+	invalids := []map[string]interface{}{
+		{"type": "signer", "account": account, "threshold": uint32(4), "lists": lists},
+		{"type": "signer", "account": account, "threshold": uint32(1), "lists": []SignerEntry{{Account: account, SignerWeight: 1}}},
+		{"type": "signer", "account": account, "threshold": uint32(1), "lists": []SignerEntry{lists[1], lists[1]}},
+		{"type": "signer", "account": account, "threshold": uint32(1)},
+		{"type": "signer", "account": account, "threshold": 1, "lists": lists},
+	}
+	for _, options := range invalids {
+		if _, err := remote.BuildAccountSetTx(options); err == nil {
+			t.Fatalf("BuildAccountSetTx %v should fail", options)
+		}
+	}
+
+	//删除多重签名列表
+	if _, err := remote.BuildAccountSetTx(map[string]interface{}{"type": "signer", "account": account, "threshold": uint32(0)}); err != nil {
+		t.Fatalf("Delete signer list fail : %s", err.Error())
+	}
+}
+
+//Test_MultiSign 两个签名者分别签名后合并，无需连接底层
+func Test_MultiSign(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	signer1, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	signer2, _ := FromSecret("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j")
+	treasury := "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"
+
+	build := func(sequence uint32) *Transaction {
+		tx, err := remote.BuildPaymentTx(treasury, "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Amount{Currency: "SWT", Value: "100"})
+		if err != nil {
+			t.Fatalf("Build payment tx fail : %s", err.Error())
+		}
+		tx.AddTxJSON("Fee", float32(30000))
+		tx.AddTxJSON("Sequence", sequence)
+		return tx
+	}
+
+	tx := build(8)
+	blob1, err := tx.SignFor(signer1)
+	if err != nil {
+		t.Fatalf("SignFor fail : %s", err.Error())
+	}
+	blob2, err := tx.SignFor(signer2)
+	if err != nil {
+		t.Fatalf("SignFor fail : %s", err.Error())
+	}
+
+	combined, err := CombineSignatures(blob2, blob1, blob2)
+	if err != nil {
+		t.Fatalf("CombineSignatures fail : %s", err.Error())
+	}
+
+	if reversed, _ := CombineSignatures(blob1, blob2); reversed != combined {
+		t.Fatalf("Combined blob depends on the order of blobs")
+	}
+
+	txJSON, err := VerifyTransaction(combined)
+	if err != nil {
+		t.Fatalf("VerifyTransaction fail : %s", err.Error())
+	}
+
+	if txJSON["SigningPubKey"] != "" || txJSON["Fee"] != "30000" {
+		t.Fatalf("Multi-signed tx %v", txJSON)
+	}
+
+	signers := txJSON["TxnSignatures"].([]interface{})
+	if len(signers) != 2 {
+		t.Fatalf("Signers %v", signers)
+	}
+
+	//签名者按 AccountID 升序排列
+	var last []byte
+	for _, item := range signers {
+		id, _ := utils.DecodeAddress(item.(map[string]interface{})["Signer"].(map[string]interface{})["Account"].(string))
+		if bytes.Compare(last, id) >= 0 {
+			t.Fatalf("Signers are not in canonical order : %v", signers)
+		}
+		last = id
+	}
+
+	//签名者的签名与交易内容绑定
+	txJSON["Sequence"] = uint32(9)
+	if err := VerifyTxJSON(txJSON); err != constant.ERR_TX_INVALID_SIGNATURE {
+		t.Fatalf("Tampered multi-signed tx err %v", err)
+	}
+
+	other, _ := build(9).SignFor(signer2)
+	if _, err := CombineSignatures(blob1, other); err == nil {
+		t.Fatalf("Combine different transactions should fail")
+	}
+
+	if _, err := CombineSignatures(); err == nil {
+		t.Fatalf("Combine nothing should fail")
+	}
+
+	mtx, err := remote.BuildMultiSignedTx(blob1, blob2)
+	if err != nil {
+		t.Fatalf("BuildMultiSignedTx fail : %s", err.Error())
+	}
+
+	if hash, _ := mtx.Hash(); hash == "" {
+		t.Fatalf("Multi-signed tx hash is empty")
+	} else if id, _ := serializer.TransactionID(combined); id != hash {
+		t.Fatalf("Hash %s, expect %s", hash, id)
+	}
+}
+
+//Test_SignForTwice 同一交易多次 SignFor 结果相同，交易本身不变，无需连接底层
+func Test_SignForTwice(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	signer, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	treasury := "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"
+
+	tx, err := remote.BuildPaymentTx(treasury, "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Amount{Currency: "SWT", Value: "100"})
+	if err != nil {
+		t.Fatalf("Build payment tx fail : %s", err.Error())
+	}
+	tx.AddTxJSON("Fee", float32(30000))
+	tx.AddTxJSON("Sequence", uint32(8))
+	tx.AddMemo("multi-sign")
+
+	memo := tx.GetTxJSON("Memos").(*list.List).Front().Value.(*serializer.MemoInfo).Memo
+	before := fmt.Sprint(tx.txJSON)
+	blob1, err := tx.SignFor(signer)
+	if err != nil {
+		t.Fatalf("SignFor fail : %s", err.Error())
+	}
+	blob2, err := tx.SignFor(signer)
+	if err != nil {
+		t.Fatalf("SignFor fail : %s", err.Error())
+	}
+
+	if blob1 != blob2 {
+		t.Fatalf("SignFor twice %s, %s", blob1, blob2)
+	}
+
+	if after := fmt.Sprint(tx.txJSON); after != before || tx.prepared || memo.MemoData != utils.StringToHex("multi-sign") {
+		t.Fatalf("SignFor changed tx_json %s, expect %s", after, before)
+	}
+
+	payment := &serializer.Payment{
+		TxCommon:    serializer.TxCommon{Account: treasury, Fee: 30000, Sequence: 8},
+		Destination: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS",
+		Amount:      constant.Amount{Currency: "SWT", Value: "100"},
+	}
+	typedTx, err := remote.BuildTx(payment)
+	if err != nil {
+		t.Fatalf("BuildTx fail : %s", err.Error())
+	}
+
+	common := fmt.Sprint(*payment.Common())
+	typedBlob, err := typedTx.SignFor(signer)
+	if err != nil {
+		t.Fatalf("SignFor fail : %s", err.Error())
+	}
+
+	if after := fmt.Sprint(*payment.Common()); after != common {
+		t.Fatalf("SignFor changed TxCommon %s, expect %s", after, common)
+	}
+
+	if again, _ := typedTx.SignFor(signer); again != typedBlob {
+		t.Fatalf("Typed SignFor %s, expect %s", again, typedBlob)
+	}
+}
@@ -0,0 +1,237 @@
+/**
+ * 节点池，Remote 同时连接多个节点，请求发送到最健康的节点，节点断开时把请求转移到其他节点。
+ *
+ * @FileName: pool.go
+ */
+package jingtumlib
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"jingtumlib/constant"
+)
+
+var (
+	//HealthCheckInterval 节点池中请求 server_info 检查节点状态的间隔
+	HealthCheckInterval = 30 * time.Second
+
+	//syncedStates 已同步的节点状态，优先选择
+	syncedStates = activeStates{"full", "validating", "proposing"}
+
+	//maxLedgerLag 落后最新账本超过该数量的节点视为不同步
+	maxLedgerLag uint64 = 3
+)
+
+//ServerHealth 节点健康状况
+type ServerHealth struct {
+	URL             string
+	State           string        //连接状态：connecting、online 或 offline
+	ServerState     string        //节点状态，如 full、syncing
+	CompleteLedgers string        //节点的完整账本区间
+	LedgerIndex     uint64        //节点最新的账本序号
+	Latency         time.Duration //请求响应时间的滑动平均
+	Errors          int           //连续的发送失败和断线次数，成功响应后清零
+}
+
+//NewRemotePool 创建连接多个节点的 Remote。请求发送到最健康的节点：优先已同步（full、validating、proposing）
+//且账本不落后的节点，其次按响应时间和连续错误次数；节点断开时等待响应的请求（submit 除外）转移到其他节点
+func NewRemotePool(urls []string, localSign bool) (*Remote, error) {
+	if len(urls) == 0 {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	return newRemote(new(Remote), urls, localSign)
+}
+
+//connectPool 同时连接所有节点，任一节点连接成功即回调成功
+func (remote *Remote) connectPool(callback func(err error, result interface{})) error {
+	var once sync.Once
+	var failed int32
+	var lastErr error
+	wg := sync.WaitGroup{}
+	for _, server := range remote.servers {
+		wg.Add(1)
+		go func(server *Server) {
+			defer wg.Done()
+			server.connect(func(err error, result interface{}) {
+				if err == nil {
+					once.Do(func() {
+						callback(nil, result)
+					})
+					return
+				}
+
+				if atomic.AddInt32(&failed, 1) == int32(len(remote.servers)) {
+					lastErr = err
+					once.Do(func() {
+						callback(err, nil)
+					})
+				}
+			}, true)
+		}(server)
+	}
+	wg.Wait()
+
+	return lastErr
+}
+
+//IsConnected 是否有在线的节点
+func (remote *Remote) IsConnected() bool {
+	for _, server := range remote.servers {
+		if server.IsConnected() {
+			return true
+		}
+	}
+
+	return false
+}
+
+//ServerHealth 各节点的健康状况
+func (remote *Remote) ServerHealth() []ServerHealth {
+	healths := make([]ServerHealth, 0, len(remote.servers))
+	for _, server := range remote.servers {
+		healths = append(healths, server.health())
+	}
+
+	return healths
+}
+
+func (server *Server) health() ServerHealth {
+	server.l.RLock()
+	defer server.l.RUnlock()
+	return ServerHealth{
+		URL:             server.url,
+		State:           server.state,
+		ServerState:     server.serverState,
+		CompleteLedgers: server.completeLedgers,
+		LedgerIndex:     server.ledgerIndex,
+		Latency:         server.latency,
+		Errors:          server.errors,
+	}
+}
+
+//rank 节点排序，值越小越健康：不同步和账本落后的节点排在后面，其次比较 (响应时间 + 1ms) * (1 + 连续错误次数)
+func (health ServerHealth) rank(maxLedger uint64) (int, time.Duration) {
+	level := 0
+	if health.ServerState != "" && !syncedStates.contain(health.ServerState) {
+		level++
+	}
+	if health.LedgerIndex+maxLedgerLag < maxLedger {
+		level++
+	}
+
+	return level, (health.Latency + time.Millisecond) * time.Duration(1+health.Errors)
+}
+
+//selectServer 选择最健康的在线节点，没有在线节点时返回 nil
+func (remote *Remote) selectServer() *Server {
+	return remote.selectServerExcept(nil)
+}
+
+//selectServerExcept 选择 except 以外最健康的在线节点，没有时返回 nil
+func (remote *Remote) selectServerExcept(except *Server) *Server {
+	var servers []*Server
+	var healths []ServerHealth
+	var maxLedger uint64
+	for _, server := range remote.servers {
+		if server == except || !server.IsConnected() {
+			continue
+		}
+
+		health := server.health()
+		if health.LedgerIndex > maxLedger {
+			maxLedger = health.LedgerIndex
+		}
+		servers = append(servers, server)
+		healths = append(healths, health)
+	}
+
+	best := -1
+	var bestLevel int
+	var bestCost time.Duration
+	for i := range healths {
+		level, cost := healths[i].rank(maxLedger)
+		if best < 0 || level < bestLevel || (level == bestLevel && cost < bestCost) {
+			best, bestLevel, bestCost = i, level, cost
+		}
+	}
+
+	if best < 0 {
+		return nil
+	}
+
+	return servers[best]
+}
+
+//broadcast 订阅和退订发送到所有支持订阅的在线节点，任一节点成功即回调成功，所有节点失败时回调最后的错误
+func (remote *Remote) broadcast(rc *ReqCtx) {
+	var servers []*Server
+	for _, server := range remote.servers {
+		if server.isSubscribable() {
+			servers = append(servers, server)
+		}
+	}
+
+	if len(servers) == 0 {
+		if remote.IsConnected() {
+			rc.callback(constant.ERR_SERVER_NOT_SUBSCRIBABLE, nil)
+		} else {
+			rc.callback(constant.ERR_SERVER_NOT_READY, nil)
+		}
+		return
+	}
+
+	var once sync.Once
+	remaining := int32(len(servers))
+	for _, server := range servers {
+		data := make(map[string]interface{}, len(rc.data))
+		for k, v := range rc.data {
+			data[k] = v
+		}
+
+		sub := &ReqCtx{command: rc.command, data: data, filter: rc.filter, callback: func(err error, result interface{}) {
+			if err == nil {
+				once.Do(func() {
+					rc.callback(nil, result)
+				})
+				return
+			}
+
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				once.Do(func() {
+					rc.callback(err, nil)
+				})
+			}
+		}}
+		if rc.ctx != nil {
+			remote.withContext(rc.ctx, sub)
+		}
+		remote.send(server, sub)
+	}
+}
+
+//failover 请求发送失败或节点断开时转移到其他在线节点，每个请求最多转移节点数次。
+//submit 可能已经被节点接收，订阅和退订已发送到所有节点，这些请求不转移，以 err 结束
+func (remote *Remote) failover(rc *ReqCtx, err error) {
+	switch rc.command {
+	case constant.CommandSubmit, constant.CommandSubscribe, constant.CommandUnSubscribe:
+	default:
+		if rc.retries < len(remote.servers) {
+			if server := remote.selectServerExcept(rc.server); server != nil {
+				rc.retries++
+				remote.send(server, rc)
+				return
+			}
+		}
+	}
+
+	rc.callback(err, nil)
+}
+
+//String 节点健康状况的描述
+func (health ServerHealth) String() string {
+	return fmt.Sprintf("%s %s/%s ledger %d latency %s errors %d", health.URL, health.State, health.ServerState, health.LedgerIndex, health.Latency, health.Errors)
+}
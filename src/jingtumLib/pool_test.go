@@ -0,0 +1,134 @@
+/**
+ * 节点池测试类，用本地 websocket 模拟多个节点
+ *
+ * @FileName: pool_test.go
+ */
+package jingtumlib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"jingtumlib/constant"
+)
+
+//Test_RemotePool 请求发送到响应最快的节点，节点断开后请求转移到其他节点，同一交易只触发一次事件
+func Test_RemotePool(t *testing.T) {
+	slow, slowSrv := newMockNode()
+	defer slowSrv.Close()
+	slow.delay = 30 * time.Millisecond
+	fast, fastSrv := newMockNode()
+
+	remote, err := NewRemotePool([]string{strings.Replace(slowSrv.URL, "http://", "ws://", 1), strings.Replace(fastSrv.URL, "http://", "ws://", 1)}, true)
+	if err != nil {
+		t.Fatalf("New remote pool fail : %s", err.Error())
+	}
+	remote.SetReconnectPolicy(&ReconnectPolicy{MinDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond})
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	defer remote.Disconnect()
+
+	//等待两个节点的状态检查完成
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		healths := remote.ServerHealth()
+		if healths[0].Latency > 0 && healths[1].Latency > 0 && healths[0].LedgerIndex == 100 && healths[1].LedgerIndex == 100 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Wait health check timeout : %v", healths)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	txs := make(chan interface{}, 10)
+	remote.On(constant.EventTX, func(data interface{}) {
+		txs <- data
+	})
+	tx := map[string]interface{}{"type": "transaction", "transaction": map[string]interface{}{"hash": "A1B2"}}
+	slow.push(tx)
+	fast.push(tx)
+	<-txs
+	select {
+	case <-txs:
+		t.Errorf("Duplicate transaction event")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	fast.l.Lock()
+	fast.hold[constant.CommandLedger] = true
+	fast.l.Unlock()
+
+	result := make(chan error, 1)
+	req, _ := remote.RequestLedger(map[string]interface{}{})
+	req.Submit(func(err error, data interface{}) {
+		result <- err
+	})
+	fast.waitRequest(t, constant.CommandLedger)
+
+	fastSrv.Listener.Close()
+	fast.drop()
+	fastSrv.Close()
+
+	slow.waitRequest(t, constant.CommandLedger)
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Request should fail over to other server, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Request not fail over")
+	}
+
+	if health := remote.ServerHealth()[1]; health.State == constant.EventOnline || health.Errors == 0 {
+		t.Errorf("Dropped server health should be offline with errors : %v", health)
+	}
+
+	if !remote.IsConnected() {
+		t.Errorf("Remote should be connected to other server")
+	}
+}
+
+//Test_SelectServer 优先已同步且账本不落后的节点，其次按响应时间和错误次数
+func Test_SelectServer(t *testing.T) {
+	remote, err := NewRemotePool([]string{"ws://127.0.0.1:5020", "ws://127.0.0.1:5021", "ws://127.0.0.1:5022"}, true)
+	if err != nil {
+		t.Fatalf("New remote pool fail : %s", err.Error())
+	}
+
+	if remote.selectServer() != nil {
+		t.Fatalf("Offline servers should not be selected")
+	}
+
+	reset := func() {
+		for i, server := range remote.servers {
+			server.connected = true
+			server.serverState = "full"
+			server.ledgerIndex = 100
+			server.errors = 0
+			server.latency = []time.Duration{50, 10, 30}[i] * time.Millisecond
+		}
+	}
+
+	tests := []struct {
+		name   string
+		update func()
+		want   int
+	}{
+		{"fastest", func() {}, 1},
+		{"not synced", func() { remote.servers[1].serverState = "syncing" }, 2},
+		{"lagging", func() { remote.servers[1].serverState = "syncing"; remote.servers[2].ledgerIndex = 90 }, 0},
+		{"errors", func() { remote.servers[1].errors = 10 }, 2},
+		{"offline", func() { remote.servers[1].connected = false }, 2},
+	}
+
+	for _, test := range tests {
+		reset()
+		test.update()
+		if server := remote.selectServer(); server != remote.servers[test.want] {
+			t.Errorf("%s : should select %s, got %s", test.name, remote.servers[test.want].url, server.url)
+		}
+	}
+}
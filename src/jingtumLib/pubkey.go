@@ -0,0 +1,177 @@
+/**
+ * 公钥、地址和 AccountID 的转换，以及只有公钥的钱包，不需要私钥即可得到地址和验证签名。
+ *
+ * @FileName: pubkey.go
+ */
+package jingtumlib
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/crypto/secp256k1"
+	"jingtumlib/utils"
+)
+
+//PublicWallet 只有公钥的钱包（只读钱包），如监控服务只需要地址和验证签名，不持有私钥
+type PublicWallet struct {
+	pubKey []byte
+}
+
+//NewPublicWallet 根据 16 进制公钥创建只读钱包：33 字节 secp256k1 压缩公钥、65 字节非压缩公钥，或 ED 开头的 ed25519 公钥
+func NewPublicWallet(pubKey string) (*PublicWallet, error) {
+	data, err := hex.DecodeString(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid public key %s", pubKey)
+	}
+
+	data, err = parsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicWallet{pubKey: data}, nil
+}
+
+//parsePublicKey 验证公钥并转成 33 字节公钥，secp256k1 公钥须在曲线上，非压缩公钥转成压缩公钥
+func parsePublicKey(pubKey []byte) ([]byte, error) {
+	if len(pubKey) == 33 && pubKey[0] == constant.Ed25519PubKeyPrefix {
+		return pubKey, nil
+	}
+
+	pub, err := secp256k1.ParsePublicKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid public key : %s", err.Error())
+	}
+
+	return pub.ToBytes(), nil
+}
+
+//GetPublicKey 获取16进制公钥
+func (wallet *PublicWallet) GetPublicKey() string {
+	return strings.ToUpper(hex.EncodeToString(wallet.pubKey))
+}
+
+//GetAddress 获取钱包地址
+func (wallet *PublicWallet) GetAddress() string {
+	return crypto.AddressFromPublicKey(wallet.pubKey)
+}
+
+//GetAccountID 获取 16 进制的 20 字节 AccountID
+func (wallet *PublicWallet) GetAccountID() string {
+	return strings.ToUpper(hex.EncodeToString(crypto.AccountIDFromPublicKey(wallet.pubKey)))
+}
+
+//GetKeyType 获取签名算法类型
+func (wallet *PublicWallet) GetKeyType() crypto.KeyType {
+	if wallet.pubKey[0] == constant.Ed25519PubKeyPrefix {
+		return crypto.Ed25519
+	}
+
+	return crypto.Secp256k1
+}
+
+//Verify 验证 Wallet.Sign 对签名数据（哈希前缀 + 序列化数据）的 16 进制签名，secp256k1 签名须为 low-S 的规范签名
+func (wallet *PublicWallet) Verify(message []byte, signature string) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return verifyKeySignature(wallet.pubKey, message, sig, true)
+}
+
+//VerifyMessage 验证 Wallet.SignMessage 的签名，同 VerifyMessage(wallet.GetAddress(), message, signature)
+func (wallet *PublicWallet) VerifyMessage(message []byte, signature string) error {
+	return VerifyMessage(wallet.GetAddress(), message, signature)
+}
+
+//PublicWallet 派生序号为 index 的只读钱包，只读钱包族也可以派生
+func (family *WalletFamily) PublicWallet(index uint32) *PublicWallet {
+	return &PublicWallet{pubKey: family.family.PublicKey(index).ToBytes()}
+}
+
+//AddressFromPublicKey 16 进制公钥转成钱包地址，公钥格式同 NewPublicWallet
+func AddressFromPublicKey(pubKey string) (string, error) {
+	wallet, err := NewPublicWallet(pubKey)
+	if err != nil {
+		return "", err
+	}
+
+	return wallet.GetAddress(), nil
+}
+
+//AccountIDFromAddress 钱包地址转成 16 进制的 20 字节 AccountID
+func AccountIDFromAddress(address string) (string, error) {
+	accountID, err := utils.DecodeB58(constant.AccountPrefix, address)
+	if err != nil || len(accountID) != 20 {
+		return "", constant.ERR_INVALID_PARAM
+	}
+
+	return strings.ToUpper(hex.EncodeToString(accountID)), nil
+}
+
+//AddressFromAccountID 16 进制的 20 字节 AccountID 转成钱包地址
+func AddressFromAccountID(accountID string) (string, error) {
+	data, err := hex.DecodeString(accountID)
+	if err != nil || len(data) != 20 {
+		return "", constant.ERR_INVALID_PARAM
+	}
+
+	return utils.EncodeB58(constant.AccountPrefix, data), nil
+}
+
+//EncodeNodePublicKey 16 进制的节点 secp256k1 公钥转成 base58 编码（如 validators 中的节点公钥）
+func EncodeNodePublicKey(pubKey string) (string, error) {
+	wallet, err := NewPublicWallet(pubKey)
+	if err != nil {
+		return "", err
+	}
+
+	if wallet.GetKeyType() != crypto.Secp256k1 {
+		return "", fmt.Errorf("Node public key must be secp256k1")
+	}
+
+	return utils.EncodeB58(constant.NodePublicPrefix, wallet.pubKey), nil
+}
+
+//DecodeNodePublicKey base58 编码的节点公钥转成 16 进制公钥
+func DecodeNodePublicKey(nodePublic string) (string, error) {
+	return decodePublicKey(constant.NodePublicPrefix, nodePublic)
+}
+
+//EncodeAccountPublicKey 16 进制的账户公钥转成 base58 编码
+func EncodeAccountPublicKey(pubKey string) (string, error) {
+	wallet, err := NewPublicWallet(pubKey)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.EncodeB58(constant.AccountPublicPrefix, wallet.pubKey), nil
+}
+
+//DecodeAccountPublicKey base58 编码的账户公钥转成 16 进制公钥
+func DecodeAccountPublicKey(accountPublic string) (string, error) {
+	return decodePublicKey(constant.AccountPublicPrefix, accountPublic)
+}
+
+func decodePublicKey(prefix uint8, encoded string) (string, error) {
+	data, err := utils.DecodeB58(prefix, encoded)
+	if err != nil {
+		return "", constant.ERR_INVALID_PARAM
+	}
+
+	pubKey, err := parsePublicKey(data)
+	if err != nil || len(data) != 33 {
+		return "", constant.ERR_INVALID_PARAM
+	}
+
+	if prefix == constant.NodePublicPrefix && pubKey[0] == constant.Ed25519PubKeyPrefix {
+		return "", constant.ERR_INVALID_PARAM
+	}
+
+	return strings.ToUpper(hex.EncodeToString(pubKey)), nil
+}
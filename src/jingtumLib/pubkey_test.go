@@ -0,0 +1,124 @@
+/**
+ * 公钥转换测试类
+ *
+ * @FileName: pubkey_test.go
+ */
+package jingtumlib
+
+import (
+	"testing"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+)
+
+//Test_PublicWallet 只读钱包的地址、AccountID 和签名验证
+func Test_PublicWallet(t *testing.T) {
+	secpPubKey := "021388E6428615BFF60744C6936E69BFDC603F9F2CA3D473B48B4A20DE171D1F04"
+	uncompressed := "041388E6428615BFF60744C6936E69BFDC603F9F2CA3D473B48B4A20DE171D1F04918D43DD9357B5340AE1D0F4FF9143521B76E90031C7C12449640D780EE6C810"
+	edPubKey := "ED01FA53FA5A7E77798F882ECE20B1ABC00BB358A9E55A202D0D0676BD0CE37A63"
+
+	for _, pubKey := range []string{secpPubKey, uncompressed} {
+		wallet, err := NewPublicWallet(pubKey)
+		if err != nil {
+			t.Fatalf("NewPublicWallet fail : %s", err.Error())
+		}
+
+		if wallet.GetPublicKey() != secpPubKey || wallet.GetAddress() != "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72" ||
+			wallet.GetAccountID() != "AA36C7655C4E4136A37D11A2A487DFDB0AE3ACD1" || wallet.GetKeyType() != crypto.Secp256k1 {
+			t.Fatalf("Public wallet %s %s %s", wallet.GetPublicKey(), wallet.GetAddress(), wallet.GetAccountID())
+		}
+	}
+
+	address, err := AddressFromPublicKey(edPubKey)
+	if err != nil || address != "jLUEXYuLiQptky37CqLcm9USQpPiz5jkpD" {
+		t.Fatalf("AddressFromPublicKey = %s, err %v", address, err)
+	}
+
+	//只读钱包验证钱包的签名
+	for _, secret := range []string{"ssc5eiFivvU2otV6bSYmJeZrAsQK3", "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"} {
+		wallet, _ := FromSecret(secret)
+		public, _ := NewPublicWallet(wallet.GetPublicKey())
+
+		message := []byte("STX data")
+		signature, _ := wallet.Sign(message)
+		if !public.Verify(message, signature) || public.Verify([]byte("STX date"), signature) {
+			t.Fatalf("Verify fail")
+		}
+
+		signature, _ = wallet.SignMessage(message)
+		if err := public.VerifyMessage(message, signature); err != nil {
+			t.Fatalf("VerifyMessage fail : %s", err.Error())
+		}
+	}
+
+	family, _ := NewWatchOnlyFamily("fhq1tyGzSPRjmLQxpz9fSqZe62gTXmkLV3qEqZnkQVVZhXwEgaNb")
+	if family.PublicWallet(1).GetAddress() != "jRfmjR4o9maVy8CYRUnN6p9Ms65r2YEz4" {
+		t.Fatalf("Family public wallet %s", family.PublicWallet(1).GetAddress())
+	}
+
+	invalid := []string{
+		"",
+		"zz",
+		secpPubKey[:64],
+		"04" + secpPubKey[2:],
+		//x = 5 不在曲线上
+		"020000000000000000000000000000000000000000000000000000000000000005",
+		//y 与 x 不匹配
+		uncompressed[:len(uncompressed)-2] + "11",
+	}
+	for _, pubKey := range invalid {
+		if _, err := NewPublicWallet(pubKey); err == nil {
+			t.Fatalf("Invalid public key %s should fail", pubKey)
+		}
+	}
+}
+
+//Test_PublicKeyEncoding 节点公钥、账户公钥和 AccountID 的 base58 转换
+func Test_PublicKeyEncoding(t *testing.T) {
+	secpPubKey := "021388E6428615BFF60744C6936E69BFDC603F9F2CA3D473B48B4A20DE171D1F04"
+	edPubKey := "ED01FA53FA5A7E77798F882ECE20B1ABC00BB358A9E55A202D0D0676BD0CE37A63"
+
+	nodePublic, err := EncodeNodePublicKey(secpPubKey)
+	if err != nil || nodePublic != "n9J6aiA9wSLAsbR2kxxtVk86Q6Tc18dXF8xSu8HpW2fKeL85nFi4" {
+		t.Fatalf("EncodeNodePublicKey = %s, err %v", nodePublic, err)
+	}
+	if pubKey, err := DecodeNodePublicKey(nodePublic); err != nil || pubKey != secpPubKey {
+		t.Fatalf("DecodeNodePublicKey = %s, err %v", pubKey, err)
+	}
+
+	for pubKey, expect := range map[string]string{
+		secpPubKey: "aB4fazTSPWkt1DWxdVr9hyTf9r1B55XcxtKMFx6gAvPJcCUN8kqP",
+		edPubKey:   "aKN8JyorqMHPKA9V1hNhCG6Lcrpjvhnq6R8ryC6PoMWQmYVGfi5B",
+	} {
+		accountPublic, err := EncodeAccountPublicKey(pubKey)
+		if err != nil || accountPublic != expect {
+			t.Fatalf("EncodeAccountPublicKey = %s, err %v", accountPublic, err)
+		}
+		if decoded, err := DecodeAccountPublicKey(accountPublic); err != nil || decoded != pubKey {
+			t.Fatalf("DecodeAccountPublicKey = %s, err %v", decoded, err)
+		}
+	}
+
+	if _, err := EncodeNodePublicKey(edPubKey); err == nil {
+		t.Fatalf("Ed25519 node public key should fail")
+	}
+	if _, err := DecodeNodePublicKey("aB4fazTSPWkt1DWxdVr9hyTf9r1B55XcxtKMFx6gAvPJcCUN8kqP"); err != constant.ERR_INVALID_PARAM {
+		t.Fatalf("Account public key as node public key err %v", err)
+	}
+
+	accountID, err := AccountIDFromAddress("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72")
+	if err != nil || accountID != "AA36C7655C4E4136A37D11A2A487DFDB0AE3ACD1" {
+		t.Fatalf("AccountIDFromAddress = %s, err %v", accountID, err)
+	}
+	if address, err := AddressFromAccountID(accountID); err != nil || address != "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72" {
+		t.Fatalf("AddressFromAccountID = %s, err %v", address, err)
+	}
+
+	if _, err := AccountIDFromAddress("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj73"); err != constant.ERR_INVALID_PARAM {
+		t.Fatalf("Invalid address err %v", err)
+	}
+	if _, err := AddressFromAccountID("AA36C7"); err != constant.ERR_INVALID_PARAM {
+		t.Fatalf("Invalid account id err %v", err)
+	}
+}
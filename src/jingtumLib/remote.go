@@ -2,17 +2,21 @@ package jingtumlib
 
 import (
 	"container/list"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"jingtumlib/constant"
 	jtLRU "jingtumlib/lruCache"
+	"jingtumlib/serializer"
 	"jingtumlib/utils"
 
 	"github.com/olebedev/emitter"
@@ -30,9 +34,12 @@ type Remote struct {
 	LocalSign bool
 	Paths     *jtLRU.LRU
 	cache     *jtLRU.LRU
-	server    *Server
+	servers   []*Server
 	emit      *emitter.Emitter
 	lock      sync.Mutex
+	streams   map[string]bool
+	cid       uint64
+	timeout   time.Duration
 }
 
 //ResData 响应结构
@@ -42,13 +49,15 @@ type ResData map[string]interface{}
 type Amount constant.Amount
 
 //ParameterInfo ParameterInfo
-type ParameterInfo struct {
-	Parameter string
-}
+type ParameterInfo = serializer.ParameterInfo
 
 //ArgInfo ArgInfo
-type ArgInfo struct {
-	Arg *ParameterInfo
+type ArgInfo = serializer.ArgInfo
+
+//SignerEntry 多重签名的签名者及权重
+type SignerEntry struct {
+	Account      string
+	SignerWeight uint16
 }
 
 //ReqCtx 请求包装类
@@ -58,6 +67,17 @@ type ReqCtx struct {
 	callback func(err error, data interface{})
 	cid      uint64
 	filter   Filter
+	server   *Server
+	sent     time.Time
+	retries  int
+	ctx      context.Context
+}
+
+//newReqCtx 创建不过滤结果的请求
+func newReqCtx(command string, data map[string]interface{}, callback func(err error, data interface{})) *ReqCtx {
+	return &ReqCtx{command: command, data: data, callback: callback, filter: func(data interface{}) interface{} {
+		return data
+	}}
 }
 
 //Remoter 提供以下方法：
@@ -125,6 +145,10 @@ type Remoter interface {
 	DeployContractTx(options map[string]interface{}) (*Transaction, error)
 	//CallContractTx 执行合约
 	CallContractTx(options map[string]interface{}) (*Transaction, error)
+	//BuildTx 根据强类型交易创建交易对象
+	BuildTx(txData serializer.TxData) (*Transaction, error)
+	//BuildMultiSignedTx 合并多重签名
+	BuildMultiSignedTx(blobs ...string) (*Transaction, error)
 }
 
 //NewRemote 创建Remote，url 为空是从配置文件获取server 地址
@@ -149,8 +173,14 @@ func NewRemote(url string, localSign bool) (*Remote, error) {
 		url += ":" + port
 	}
 
+	return newRemote(remote, []string{url}, localSign)
+}
+
+func newRemote(remote *Remote, urls []string, localSign bool) (*Remote, error) {
 	remote.requests = make(map[uint64]*ReqCtx)
 	remote.status = make(map[string]interface{})
+	remote.streams = make(map[string]bool)
+	remote.timeout = DefaultRequestTimeout
 	remote.lock = sync.Mutex{}
 	lru, err := jtLRU.NewLRU(100, time.Duration(5)*time.Minute, nil)
 	if err != nil {
@@ -163,26 +193,33 @@ func NewRemote(url string, localSign bool) (*Remote, error) {
 		return remote, err
 	}
 	remote.LocalSign = localSign
-	server, err := NewServer(remote, url)
-	if err != nil {
-		return remote, err
+	for _, url := range urls {
+		server, err := NewServer(remote, url)
+		if err != nil {
+			return remote, err
+		}
+
+		remote.servers = append(remote.servers, server)
 	}
 
-	remote.server = server
 	remote.emit = &emitter.Emitter{}
 	remote.emit.Use("*", emitter.Void)
 
 	return remote, nil
 }
 
-//Connect 连接函数
+//Connect 连接函数。节点池同时连接所有节点，任一节点连接成功即回调成功，连接失败的节点在后台重连
 func (remote *Remote) Connect(callback func(err error, result interface{})) error {
-	if remote.server == nil {
+	if len(remote.servers) == 0 {
 		callback(constant.ERR_SERVER_NOT_READY, nil)
 		return constant.ERR_SERVER_NOT_READY
 	}
 
-	return remote.server.connect(callback)
+	if len(remote.servers) == 1 {
+		return remote.servers[0].connect(callback, false)
+	}
+
+	return remote.connectPool(callback)
 }
 
 //GetNowTime 获取当前时间。格式(2006-01-02 15:04:05)
@@ -193,11 +230,34 @@ func (remote *Remote) GetNowTime() string {
 
 //Disconnect 关闭连接
 func (remote *Remote) Disconnect() {
-	if remote.server != nil && remote.server.Disconnect() {
-		//清除请求缓存
+	if len(remote.servers) > 0 {
+		wg := sync.WaitGroup{}
+		for _, server := range remote.servers {
+			wg.Add(1)
+			go func(server *Server) {
+				defer wg.Done()
+				server.Disconnect()
+			}(server)
+		}
+		wg.Wait()
+
+		//清除请求缓存和订阅记录
+		remote.lock.Lock()
 		for id := range remote.requests {
 			delete(remote.requests, id)
 		}
+		for stream := range remote.streams {
+			delete(remote.streams, stream)
+		}
+		remote.lock.Unlock()
+	}
+}
+
+//SetReconnectPolicy 设置断线重连策略，默认为 DefaultReconnectPolicy，nil 为断线后不重连。
+//连接状态变化时触发 EventConnecting、EventOnline 和 EventOffline 事件，事件数据为 ConnectionEvent
+func (remote *Remote) SetReconnectPolicy(policy *ReconnectPolicy) {
+	for _, server := range remote.servers {
+		server.setReconnectPolicy(policy)
 	}
 }
 
@@ -503,18 +563,178 @@ func (remote *Remote) UnSubscribe(streams []string) *Request {
 	return req
 }
 
-//Submit 提交请求
+//Submit 提交请求。请求发送到最健康的节点，订阅和退订发送到所有在线节点
 func (remote *Remote) Submit(command string, data map[string]interface{}, filter Filter, callback func(err error, data interface{})) {
+	remote.SubmitContext(context.Background(), command, data, filter, callback)
+}
+
+//SubmitContext 提交请求，ctx 取消或超时时结束请求并回调 ctx.Err() 或 *TimeoutError。
+//同时受默认超时（SetRequestTimeout）限制，回调只调用一次
+func (remote *Remote) SubmitContext(ctx context.Context, command string, data map[string]interface{}, filter Filter, callback func(err error, data interface{})) {
 	rc := new(ReqCtx)
 	rc.command = command
 	rc.data = data
 	rc.callback = callback
 	rc.filter = filter
-	rc.cid = remote.server.GetCid()
+	if command == constant.CommandSubscribe || command == constant.CommandUnSubscribe {
+		//各节点的请求分别受 ctx 和默认超时限制
+		rc.ctx = ctx
+		rc.callback = remote.trackStreams(command, data, callback)
+		remote.broadcast(rc)
+		return
+	}
+
+	server := remote.selectServer()
+	if server == nil {
+		callback(constant.ERR_SERVER_NOT_READY, nil)
+		return
+	}
+
+	remote.withContext(ctx, rc)
+	remote.send(server, rc)
+}
+
+//SetRequestTimeout 设置请求的默认超时时间，默认为 DefaultRequestTimeout，0 为不超时
+func (remote *Remote) SetRequestTimeout(timeout time.Duration) {
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+	remote.timeout = timeout
+}
+
+//withContext 设置请求的 ctx（加上默认超时），回调只调用一次，ctx 结束时删除等待响应的请求
+func (remote *Remote) withContext(ctx context.Context, rc *ReqCtx) {
+	remote.lock.Lock()
+	timeout := remote.timeout
+	remote.lock.Unlock()
+
+	cancel := func() {}
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	rc.ctx = ctx
+
+	var once sync.Once
+	callback := rc.callback
+	rc.callback = func(err error, data interface{}) {
+		once.Do(func() {
+			cancel()
+			callback(err, data)
+		})
+	}
+
+	if ctx.Done() == nil {
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		remote.lock.Lock()
+		pending := remote.requests[rc.cid] == rc
+		if pending {
+			delete(remote.requests, rc.cid)
+		}
+		remote.lock.Unlock()
+
+		if pending {
+			err := requestError(rc, ctx.Err())
+			if _, ok := err.(*TimeoutError); ok {
+				rc.server.recordError()
+			}
+			rc.callback(err, nil)
+		}
+	}()
+}
+
+//send 把请求发送到指定节点，请求的 ctx 已经结束时直接回调错误
+func (remote *Remote) send(server *Server, rc *ReqCtx) {
+	if rc.ctx == nil {
+		remote.withContext(context.Background(), rc)
+	}
+
 	remote.lock.Lock()
+	if err := rc.ctx.Err(); err != nil {
+		remote.lock.Unlock()
+		rc.callback(requestError(rc, err), nil)
+		return
+	}
+	rc.server = server
+	rc.cid = remote.nextCid()
 	remote.requests[rc.cid] = rc
 	remote.lock.Unlock()
-	remote.server.sendMessage(rc)
+	server.sendMessage(rc)
+}
+
+//isPending 请求是否仍在等待该节点响应，超时、取消或转移的请求不再发送
+func (remote *Remote) isPending(rc *ReqCtx, server *Server) bool {
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+	return remote.requests[rc.cid] == rc && rc.server == server
+}
+
+//nextCid 请求序列递增
+func (remote *Remote) nextCid() uint64 {
+	return atomic.AddUint64(&remote.cid, 1)
+}
+
+//trackStreams 订阅或退订成功后记录当前订阅的消息，断线重连后重新订阅
+func (remote *Remote) trackStreams(command string, data map[string]interface{}, callback func(err error, data interface{})) func(err error, data interface{}) {
+	streams, _ := data["streams"].([]string)
+	return func(err error, result interface{}) {
+		if err == nil {
+			remote.lock.Lock()
+			for _, stream := range streams {
+				if command == constant.CommandSubscribe {
+					remote.streams[stream] = true
+				} else {
+					delete(remote.streams, stream)
+				}
+			}
+			remote.lock.Unlock()
+		}
+		callback(err, result)
+	}
+}
+
+//activeStreams 默认订阅的消息和当前订阅的消息
+func (remote *Remote) activeStreams() []string {
+	streams := append([]string{}, defaultStreams...)
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+	for stream := range remote.streams {
+		if !activeStates(defaultStreams).contain(stream) {
+			streams = append(streams, stream)
+		}
+	}
+	sort.Strings(streams[len(defaultStreams):])
+	return streams
+}
+
+//removeRequest 取出等待响应的请求
+func (remote *Remote) removeRequest(cid uint64) (*ReqCtx, bool) {
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+	rc, ok := remote.requests[cid]
+	if ok {
+		delete(remote.requests, cid)
+	}
+	return rc, ok
+}
+
+//failRequests 节点连接断开时转移或以 err 结束该节点等待响应的请求
+func (remote *Remote) failRequests(server *Server, err error) {
+	var requests []*ReqCtx
+	remote.lock.Lock()
+	for cid, rc := range remote.requests {
+		if rc.server == server {
+			delete(remote.requests, cid)
+			requests = append(requests, rc)
+		}
+	}
+	remote.lock.Unlock()
+
+	for _, rc := range requests {
+		remote.failover(rc, err)
+	}
 }
 
 //On 监听特定的事件消息
@@ -527,15 +747,12 @@ func (remote *Remote) On(eventName string, callback func(data interface{})) {
 }
 
 func (remote *Remote) handleResponse(data ResData) {
-	remote.lock.Lock()
-	request, ok := remote.requests[data.getUint64("id")]
-	remote.lock.Unlock()
+	request, ok := remote.removeRequest(data.getUint64("id"))
 	if !ok {
 		log.Printf("Request id error %d", data.getUint64("id"))
 		return
 	}
-
-	delete(remote.requests, data.getUint64("id"))
+	request.server.recordLatency(time.Since(request.sent))
 
 	if data.getString("status") == "success" {
 		result := request.filter(data.getMap("result"))
@@ -554,14 +771,20 @@ func (remote *Remote) handlePathFind(data ResData) {
 	go remote.emit.Emit(constant.EventPathFind, data)
 }
 
+//handleTransaction 节点池的每个节点都会推送交易，同一交易只触发一次事件
 func (remote *Remote) handleTransaction(data ResData) {
 	if txHash, ok := data.getMap("transaction")["hash"].(string); ok {
+		remote.lock.Lock()
+		duplicate := remote.cache.Contains(txHash)
 		remote.cache.Add(txHash, 1)
-		go remote.emit.Emit(constant.EventTX, data)
+		remote.lock.Unlock()
+		if !duplicate {
+			go remote.emit.Emit(constant.EventTX, data)
+		}
 	}
 }
 
-func (remote *Remote) updateServerStatus(data ResData) {
+func (remote *Remote) updateServerStatus(server *Server, data ResData) {
 	remote.lock.Lock()
 	defer remote.lock.Unlock()
 	remote.status["load_base"] = data.getObj("load_base")
@@ -575,15 +798,17 @@ func (remote *Remote) updateServerStatus(data ResData) {
 	if onlineStates.contain(serverStatus) {
 		online = "online"
 	}
-	remote.server.setState(online)
+	server.updateLedger(data)
+	server.setState(online)
 }
 
-func (remote *Remote) handleServerStatus(data ResData) {
-	remote.updateServerStatus(data)
+func (remote *Remote) handleServerStatus(server *Server, data ResData) {
+	remote.updateServerStatus(server, data)
 	go remote.emit.Emit(constant.EventServerStatus, data)
 }
 
-func (remote *Remote) handleLedgerClosed(data ResData) {
+func (remote *Remote) handleLedgerClosed(server *Server, data ResData) {
+	server.updateLedger(data)
 	remote.lock.Lock()
 	defer remote.lock.Unlock()
 	stsIdx, ok := remote.status["ledger_index"]
@@ -601,7 +826,7 @@ func (remote *Remote) handleLedgerClosed(data ResData) {
 }
 
 //消息处理方法
-func (remote *Remote) handleMessage(msg []byte) {
+func (remote *Remote) handleMessage(server *Server, msg []byte) {
 	var data ResData
 	err := json.Unmarshal(msg, &data)
 	if err != nil {
@@ -620,9 +845,9 @@ func (remote *Remote) handleMessage(msg []byte) {
 	resType := data.getString("type")
 	switch resType {
 	case "ledgerClosed":
-		remote.handleLedgerClosed(data)
+		remote.handleLedgerClosed(server, data)
 	case "serverStatus":
-		remote.handleServerStatus(data)
+		remote.handleServerStatus(server, data)
 	case "response":
 		remote.handleResponse(data)
 	case "transaction":
@@ -886,12 +1111,83 @@ func (remote *Remote) BuildDelegateKeySet(options map[string]interface{}, tx *Tr
 	return nil
 }
 
-//BuildSignerSet BuildSignerSet
+//BuildSignerSet 设置多重签名列表。threshold 为 uint32 的签名门限，lists 为 []SignerEntry，
+//签名者的权重之和达到门限时交易生效；threshold 为 0 且 lists 为空时删除多重签名列表。
+//暂未完整支持：SignerQuorum、SignerEntries、SignerEntry、SignerWeight 的字段编码未经底层（skywelld）确认，
+//序列化器不能编码该交易，须以 SetSecret 由底层签名，本地签名和多重签名返回 ERR_TX_SERVER_SIGN_ONLY
 func (remote *Remote) BuildSignerSet(options map[string]interface{}, tx *Transaction) error {
-	// TODO
+	var srcAddr string
+	if src, ok := options["source"].(string); ok {
+		srcAddr = src
+	} else if from, ok := options["from"].(string); ok {
+		srcAddr = from
+	} else if account, ok := options["account"].(string); ok {
+		srcAddr = account
+	}
+
+	if !utils.IsValidAddress(srcAddr) {
+		return fmt.Errorf("invalid source address")
+	}
+
+	threshold, ok := options["threshold"].(uint32)
+	if !ok {
+		return fmt.Errorf("invalid threshold")
+	}
+
+	lists, _ := options["lists"].([]SignerEntry)
+	if (threshold == 0) != (len(lists) == 0) {
+		return fmt.Errorf("threshold and signer lists should be both set or both empty")
+	}
+
+	var weights uint32
+	signers := make(map[string]bool, len(lists))
+	entries := make([]interface{}, 0, len(lists))
+	for _, entry := range lists {
+		if !utils.IsValidAddress(entry.Account) || entry.Account == srcAddr {
+			return fmt.Errorf("invalid signer address %s", entry.Account)
+		}
+		if signers[entry.Account] {
+			return fmt.Errorf("duplicate signer address %s", entry.Account)
+		}
+		if entry.SignerWeight == 0 {
+			return fmt.Errorf("invalid signer weight of %s", entry.Account)
+		}
+
+		signers[entry.Account] = true
+		weights += uint32(entry.SignerWeight)
+		entries = append(entries, map[string]interface{}{"SignerEntry": map[string]interface{}{"Account": entry.Account, "SignerWeight": entry.SignerWeight}})
+	}
+
+	if threshold > weights {
+		return fmt.Errorf("threshold %d is greater than the sum of signer weights %d", threshold, weights)
+	}
+
+	tx.AddTxJSON("TransactionType", "SignerListSet")
+	tx.AddTxJSON("Account", srcAddr)
+	tx.AddTxJSON("SignerQuorum", threshold)
+	if len(entries) > 0 {
+		tx.AddTxJSON("SignerEntries", entries)
+	}
 	return nil
 }
 
+//BuildMultiSignedTx 合并各签名者 SignFor 得到的 blob，返回的交易提交合并后的 blob
+func (remote *Remote) BuildMultiSignedTx(blobs ...string) (*Transaction, error) {
+	blob, err := CombineSignatures(blobs...)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := NewTransaction(remote, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.AddTxJSON("TransactionType", "Signer")
+	tx.AddTxJSON("blob", blob)
+	return tx, nil
+}
+
 //BuildAccountSetTx 创建属性对象
 func (remote *Remote) BuildAccountSetTx(options map[string]interface{}) (*Transaction, error) {
 	tx, err := NewTransaction(remote, nil)
@@ -984,7 +1280,7 @@ func (remote *Remote) BuildOfferCreateTx(options map[string]interface{}) (*Trans
 
 	tx.AddTxJSON("TransactionType", "OfferCreate")
 	if offerType == "Sell" {
-		tx.SetFlags(offerType)
+		tx.SetFlags([]string{offerType})
 	}
 	tx.AddTxJSON("Account", src)
 	takerpays, err := utils.ToAmount(constant.Amount(takerPaysAmount))
@@ -1141,6 +1437,30 @@ func (remote *Remote) CallContractTx(options map[string]interface{}) (*Transacti
 	return tx, nil
 }
 
+//BuildTx 根据强类型交易（如 *serializer.Payment）创建交易对象，Fee 为 0 时使用配置的手续费
+func (remote *Remote) BuildTx(txData serializer.TxData) (*Transaction, error) {
+	if txData == nil {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	tx, err := NewTransaction(remote, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	common := txData.Common()
+	if !utils.IsValidAddress(common.Account) {
+		return nil, constant.ERR_PAYMENT_INVALID_SRC_ADDR
+	}
+
+	if common.Fee == 0 {
+		common.Fee = serializer.Drops(JTConfig.ReadInt("Config", "fee", 10000))
+	}
+
+	tx.txData = txData
+	return tx, nil
+}
+
 func (resData ResData) getUint64(key string) uint64 {
 	if ret, ok := (resData)[key]; ok {
 		switch v := ret.(type) {
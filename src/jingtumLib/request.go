@@ -8,14 +8,48 @@
 package jingtumlib
 
 import (
+	"context"
 	"fmt"
 	"jingtumlib/constant"
 	"jingtumlib/utils"
+	"time"
 )
 
+//DefaultRequestTimeout 请求的默认超时时间，可以用 Remote.SetRequestTimeout 修改
+var DefaultRequestTimeout = 30 * time.Second
+
 //Filter 过滤函数
 type Filter func(interface{}) interface{}
 
+//TimeoutError 请求超时错误，默认超时或 ctx 的截止时间到期时返回。
+//submit 超时后交易仍可能被节点接受，应按交易哈希查询结果，不能直接重新提交
+type TimeoutError struct {
+	Command string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("Request %s timeout", e.Command)
+}
+
+//Timeout 实现 net.Error 的 Timeout 方法
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+//Unwrap errors.Is(err, context.DeadlineExceeded) 为 true
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+//requestError ctx 结束的错误，超时转成 *TimeoutError
+func requestError(rc *ReqCtx, err error) error {
+	if err == context.DeadlineExceeded {
+		return &TimeoutError{Command: rc.command}
+	}
+
+	return err
+}
+
 //Request 请求结构
 type Request struct {
 	remote  *Remote
@@ -41,7 +75,12 @@ func NewRequest(remote *Remote, command string, filter Filter) *Request {
 
 //Submit 提交请求
 func (req *Request) Submit(callback func(err error, data interface{})) {
-	if !req.remote.server.IsConnected() {
+	req.SubmitContext(context.Background(), callback)
+}
+
+//SubmitContext 提交请求，ctx 取消或超时时回调 ctx.Err() 或 *TimeoutError
+func (req *Request) SubmitContext(ctx context.Context, callback func(err error, data interface{})) {
+	if !req.remote.IsConnected() {
 		callback(fmt.Errorf("Server not connected"), nil)
 		return
 	}
@@ -51,7 +90,7 @@ func (req *Request) Submit(callback func(err error, data interface{})) {
 		return
 	}
 
-	req.remote.Submit(req.command, req.message, req.filter, callback)
+	req.remote.SubmitContext(ctx, req.command, req.message, req.filter, callback)
 }
 
 //SelectLedger 选择账本
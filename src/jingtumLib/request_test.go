@@ -10,10 +10,16 @@
 package jingtumlib
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"jingtumlib/constant"
+	"jingtumlib/serializer"
 	"sync"
 	"testing"
+	"time"
+	"unicode"
 )
 
 //Test_ListenerEvent 监听账本消息
@@ -401,6 +407,87 @@ func Test_RequestLedger(t *testing.T) {
 	wg.Wait()
 }
 
+//Test_ChainTxHash 取底层账本中的交易，由序列化器重新编码后计算哈希，须与链上的交易哈希一致
+func Test_ChainTxHash(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err.Error())
+	}
+
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+
+	defer remote.Disconnect()
+
+	request := func(req *Request) (map[string]interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		done := make(chan struct{})
+		var data map[string]interface{}
+		var reqErr error
+		req.SubmitContext(ctx, func(err error, result interface{}) {
+			data, _ = result.(map[string]interface{})
+			reqErr = err
+			close(done)
+		})
+		<-done
+
+		if reqErr == nil && data == nil {
+			reqErr = fmt.Errorf("Unexpected result")
+		}
+		return data, reqErr
+	}
+
+	req, _ := remote.RequestLedger(map[string]interface{}{"transactions": true, "ledger_index": "969054", "ledger_hash": "AEE4B16B543D8C8924F09C1DB822C6419780B86019F5F5FF8DC2938E7E0E89D2"})
+	ledger, err := request(req)
+	if err != nil {
+		t.Fatalf("Fail request ledger %s", err.Error())
+	}
+
+	hashes := []interface{}{"6537F72CE1DBD8043230C3FF64C6E5E95B11F6573D91EF6A13FEADE6940CB71A"}
+	if txs, ok := ledger["transactions"].([]interface{}); ok {
+		hashes = append(hashes, txs...)
+	}
+
+	types := make(map[string]int)
+	for _, item := range hashes {
+		hash, _ := item.(string)
+		req, err := remote.RequestTx(hash)
+		if err != nil {
+			t.Fatalf("Fail request tx %v", item)
+		}
+
+		tx, err := request(req)
+		if err != nil {
+			t.Fatalf("Fail request tx %s : %s", hash, err.Error())
+		}
+
+		//hash、meta、inLedger、date、validated 等小写字段不是交易字段
+		txJSON := make(map[string]interface{}, len(tx))
+		for k, v := range tx {
+			if k != "" && unicode.IsUpper(rune(k[0])) {
+				txJSON[k] = v
+			}
+		}
+
+		so, err := serializer.FromTxJSON(txJSON)
+		if err != nil {
+			t.Fatalf("Serialize tx %s fail : %s", hash, err.Error())
+		}
+		id, _ := serializer.TransactionID(so.ToHex())
+		so.Release()
+
+		if id != hash {
+			t.Fatalf("Tx %s (%v) hash %s", hash, txJSON["TransactionType"], id)
+		}
+		types[fmt.Sprint(txJSON["TransactionType"])]++
+	}
+
+	t.Logf("Checked chain transactions %v", types)
+}
+
 // Test_RequestLedgerClosed 获取最新账本
 func Test_RequestLedgerClosed(t *testing.T) {
 	remote, err := NewRemote("ws://123.57.219.57:5020", true)
@@ -1105,3 +1192,84 @@ func BenchmarkRequestAccountInfo(b *testing.B) {
 		wg.Wait()
 	}
 }
+
+//Test_RequestTimeout 节点不响应时请求按默认超时或 ctx 结束，并删除等待响应的请求
+func Test_RequestTimeout(t *testing.T) {
+	node, srv := newMockNode()
+	defer srv.Close()
+
+	remote := mockRemote(t, srv)
+	remote.SetRequestTimeout(50 * time.Millisecond)
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	defer remote.Disconnect()
+	node.waitRequest(t, constant.CommandSubscribe)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelDeadline()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		cancel  bool
+		timeout bool
+	}{
+		{"default timeout", context.Background(), false, true},
+		{"context deadline", deadline, false, true},
+		{"context canceled", canceled, true, false},
+	}
+
+	for _, test := range tests {
+		var calls int32
+		var l sync.Mutex
+		result := make(chan error, 2)
+		req, _ := remote.RequestLedgerClosed()
+		req.SubmitContext(test.ctx, func(err error, data interface{}) {
+			l.Lock()
+			calls++
+			l.Unlock()
+			result <- err
+		})
+
+		var err error
+		select {
+		case err = <-result:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s : request not finished", test.name)
+		}
+
+		timeoutErr, ok := err.(*TimeoutError)
+		if test.timeout && (!ok || timeoutErr.Command != constant.CommandLedgerClosed || !errors.Is(err, context.DeadlineExceeded)) {
+			t.Errorf("%s : should be TimeoutError, got %v", test.name, err)
+		}
+		if test.cancel && err != context.Canceled {
+			t.Errorf("%s : should be context.Canceled, got %v", test.name, err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		l.Lock()
+		if calls != 1 {
+			t.Errorf("%s : callback should be called once, got %d", test.name, calls)
+		}
+		l.Unlock()
+
+		remote.lock.Lock()
+		if len(remote.requests) != 0 {
+			t.Errorf("%s : pending requests should be removed, got %d", test.name, len(remote.requests))
+		}
+		remote.lock.Unlock()
+	}
+
+	//超时不影响有响应的请求
+	req, _ := remote.RequestLedger(map[string]interface{}{})
+	result := make(chan error, 1)
+	req.SubmitContext(context.Background(), func(err error, data interface{}) {
+		result <- err
+	})
+	if err := <-result; err != nil {
+		t.Errorf("Request should success, got %v", err)
+	}
+}
@@ -14,6 +14,7 @@ package serializer
 
 import (
 	"fmt"
+	"strconv"
 
 	"jingtumlib/constant"
 	"jingtumlib/utils"
@@ -39,20 +40,33 @@ var (
 
 	//TYPES_MAP 序列化类型初始化
 	typesMap = map[uint8]ISerializedType{1: new(SerializedInt16), 2: STInt32, 3: new(SerializedInt64), 4: new(SerializedHash128), 5: STHash256, 6: new(SerializedAmount), 7: new(SerializedVariableLength), 8: new(SerializedAccount), 14: STObject, 15: new(SerializedArray), 16: STInt8, 17: new(SerializedHash160), 18: new(SerializedPathSet), 19: new(SerializedVector256)}
+
+	//transactionResults 交易结果编码
+	transactionResults = map[uint8]string{0: "tesSUCCESS", 100: "tecCLAIM", 101: "tecPATH_PARTIAL", 102: "tecUNFUNDED_ADD", 103: "tecUNFUNDED_OFFER", 104: "tecUNFUNDED_PAYMENT", 105: "tecFAILED_PROCESSING", 121: "tecDIR_FULL", 122: "tecINSUF_RESERVE_LINE", 123: "tecINSUF_RESERVE_OFFER", 124: "tecNO_DST", 125: "tecNO_DST_INSUF_SWT", 126: "tecNO_LINE_INSUF_RESERVE", 127: "tecNO_LINE_REDUNDANT", 128: "tecPATH_DRY", 129: "tecUNFUNDED", 130: "tecMASTER_DISABLED", 131: "tecNO_REGULAR_KEY", 132: "tecOWNERS", 133: "tecNO_ISSUER", 134: "tecNO_AUTH", 135: "tecNO_LINE", 136: "tecINSUFF_FEE", 137: "tecFROZEN", 138: "tecNO_TARGET", 139: "tecNO_PERMISSION", 140: "tecNO_ENTRY", 141: "tecINSUFFICIENT_RESERVE", 142: "tecNEED_MASTER_KEY", 143: "tecDST_TAG_NEEDED", 144: "tecINTERNAL", 145: "tecOVERSIZE"}
+
+	//fieldsMap 字段映射，由 constant.InverseFieldsMap 反转得到：类型编码 -> 字段编码 -> 字段名
+	fieldsMap = make(map[int]map[int]string)
 )
 
+func init() {
+	for name, kvp := range constant.InverseFieldsMap {
+		if _, ok := fieldsMap[kvp.Key]; !ok {
+			fieldsMap[kvp.Key] = make(map[int]string)
+		}
+		fieldsMap[kvp.Key][kvp.Value] = name
+	}
+}
+
 //SerializeHex 16进制序列化
 func SerializeHex(so *Serializer, val string, noLength bool) {
 	bytes, err := utils.HexToBytes(val)
 
 	if err != nil {
-		panic(fmt.Sprintf("Invalid hex string %v", val))
-	}
-
-	if len(bytes) == 0 {
-		bytes = []byte{0}
+		so.err = fmt.Errorf("Invalid hex string %v", val)
+		return
 	}
 
+	//空值只写长度 0，如多重签名交易的 SigningPubKey
 	if !noLength {
 		SerializeVarint(so, uint(len(bytes)))
 	}
@@ -80,6 +94,28 @@ func SerializeVarint(so *Serializer, val uint) {
 	}
 }
 
+//ParseVarint int反序列化。
+func ParseVarint(so *Serializer) uint {
+	b1 := uint(so.readByte())
+	if so.err != nil {
+		return 0
+	}
+
+	if b1 <= 192 {
+		return b1
+	} else if b1 <= 240 {
+		b2 := uint(so.readByte())
+		return 193 + (b1-193)*256 + b2
+	} else if b1 <= 254 {
+		b2 := uint(so.readByte())
+		b3 := uint(so.readByte())
+		return 12481 + (b1-241)*65536 + b2*256 + b3
+	}
+
+	so.err = fmt.Errorf("Invalid varint length indicator %d", b1)
+	return 0
+}
+
 func getLedgerEntryType(structure interface{}) (interface{}, error) {
 	var output interface{}
 	switch v := structure.(type) {
@@ -131,7 +167,7 @@ func getLedgerEntryType(structure interface{}) (interface{}, error) {
 		case "SkywellState":
 			output = 114
 		default:
-			output = 0
+			return nil, fmt.Errorf("Invalid ledger entry type %s", v)
 		}
 	default:
 		output = "UndefinedLedgerEntry"
@@ -139,6 +175,24 @@ func getLedgerEntryType(structure interface{}) (interface{}, error) {
 	return output, nil
 }
 
+func getTransactionResult(structure interface{}) (interface{}, error) {
+	switch v := structure.(type) {
+	case uint8:
+		if result, ok := transactionResults[v]; ok {
+			return result, nil
+		}
+		return nil, fmt.Errorf("Invalid transaction result %d", v)
+	case string:
+		for code, result := range transactionResults {
+			if result == v {
+				return code, nil
+			}
+		}
+		return nil, fmt.Errorf("Invalid transaction result %s", v)
+	}
+	return nil, fmt.Errorf("Invalid input type for transaction result %v. Type %T", structure, structure)
+}
+
 func getTransactionType(structure interface{}) (interface{}, error) {
 	var output interface{}
 	switch v := structure.(type) {
@@ -220,6 +274,11 @@ func Serialize(so *Serializer, fieldName string, value interface{}) {
 		return
 	}
 
+	serializeField(so, fieldName, fieldCoordinates, value)
+}
+
+//serializeField 按已查到的字段编码序列化属性
+func serializeField(so *Serializer, fieldName string, fieldCoordinates *constant.KeyValuePair, value interface{}) {
 	typeBits := fieldCoordinates.Key
 	fieldBits := fieldCoordinates.Value
 	var temp uint8
@@ -245,8 +304,18 @@ func Serialize(so *Serializer, fieldName string, value interface{}) {
 				return
 			}
 			value = v
+		} else if fieldName == "TransactionType" {
+			//交易类型可以是名称（如 Payment）或编码字符串（如 0）
+			if code, err := strconv.ParseUint(v, 10, 16); err == nil {
+				value = uint16(code)
+			} else if code, err := getTransactionType(v); err == nil {
+				value = code
+			} else {
+				so.err = err
+				return
+			}
 		} else if fieldName == "TransactionResult" {
-			v, err := getTransactionType(v)
+			v, err := getTransactionResult(v)
 			if err != nil {
 				so.err = err
 				return
@@ -255,23 +324,97 @@ func Serialize(so *Serializer, fieldName string, value interface{}) {
 		}
 	}
 
-	STInt8.Serialize(so, byte(tagByte), false)
+	so.Buffer = append(so.Buffer, tagByte)
 
 	if typeBits >= 16 {
-		STInt8.Serialize(so, byte(typeBits), false)
+		so.Buffer = append(so.Buffer, byte(typeBits))
 	}
 
 	if fieldBits >= 16 {
-		STInt8.Serialize(so, byte(fieldBits), false)
+		so.Buffer = append(so.Buffer, byte(fieldBits))
 	}
 
 	var serializedType ISerializedType
 
 	if _, ok := value.(*MemoDataInfo); ok && fieldName == "Memo" {
 		serializedType = STMemo
+	} else if _, ok := value.(*ParameterInfo); ok && fieldName == "Arg" {
+		serializedType = STArg
 	} else {
 		serializedType = typesMap[uint8(typeBits)]
 	}
 
+	if serializedType == nil {
+		so.err = fmt.Errorf("Unknown type %d of field %s", typeBits, fieldName)
+		return
+	}
+
 	serializedType.Serialize(so, value, false)
 }
+
+//Parse 反序列化属性，返回字段名及字段值
+func Parse(so *Serializer) (string, interface{}) {
+	tagByte := so.readByte()
+	typeBits := int(tagByte >> 4)
+	if typeBits == 0 {
+		typeBits = int(so.readByte())
+	}
+
+	fieldBits := int(tagByte & 0x0f)
+	if fieldBits == 0 {
+		fieldBits = int(so.readByte())
+	}
+
+	if so.err != nil {
+		return "", nil
+	}
+
+	fieldName, ok := fieldsMap[typeBits][fieldBits]
+	if !ok {
+		so.err = fmt.Errorf("Unknown field type %d, field %d", typeBits, fieldBits)
+		return "", nil
+	}
+
+	var serializedType ISerializedType
+	switch fieldName {
+	case "Memo":
+		serializedType = STMemo
+	case "Arg":
+		serializedType = STArg
+	default:
+		serializedType, ok = typesMap[uint8(typeBits)]
+		if !ok {
+			so.err = fmt.Errorf("Unsupported serialized type %d of field %s", typeBits, fieldName)
+			return "", nil
+		}
+	}
+
+	value := serializedType.Parse(so)
+	if so.err != nil {
+		return "", nil
+	}
+
+	if v, ok := value.(uint16); ok {
+		var err error
+		if fieldName == "LedgerEntryType" {
+			value, err = getLedgerEntryType(uint8(v))
+		} else if fieldName == "TransactionType" {
+			value, err = getTransactionType(uint8(v))
+		}
+		if err != nil {
+			so.err = err
+			return "", nil
+		}
+	}
+
+	if v, ok := value.(uint8); ok && fieldName == "TransactionResult" {
+		var err error
+		value, err = getTransactionResult(v)
+		if err != nil {
+			so.err = err
+			return "", nil
+		}
+	}
+
+	return fieldName, value
+}
@@ -15,11 +15,14 @@ package serializer
 import (
 	"bytes"
 	"container/list"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
-	"strconv"
+	"math/big"
+	"strings"
+	"unicode"
 
 	"jingtumlib/constant"
 	jtUtils "jingtumlib/utils"
@@ -43,12 +46,12 @@ type ISerializedType interface {
 
 // PathComputed 结构体。
 type PathComputed struct {
-	Currency string `json:"currency"`
-	Issuer   string `json:"issuer"`
-	Value    string `json:"value"`
-	Account  string `json:"account"`
-	Type     int    `json:"type"`
-	TypeHex  string `json:"type_hex"`
+	Currency string `json:"currency,omitempty"`
+	Issuer   string `json:"issuer,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Account  string `json:"account,omitempty"`
+	Type     int    `json:"type,omitempty"`
+	TypeHex  string `json:"type_hex,omitempty"`
 }
 
 // PathData 结构体。
@@ -124,13 +127,19 @@ type SerializedAccount struct {
 //Serialize int8
 func (serInt8 SerializedInt8) Serialize(so *Serializer, val interface{}, noMarker bool) {
 	if vuit8, ok := val.(uint8); ok {
-		so.Append(jtUtils.GetBytes(vuit8))
+		so.appendUint(uint64(vuit8), 1)
 	} else if vint, ok := val.(int); ok {
 		if vint > math.MaxUint8 || vint < 0 {
 			so.err = fmt.Errorf("Value out of bounds %d", vint)
 			return
 		}
-		so.Append(jtUtils.GetBytes(uint8(vint)))
+		so.appendUint(uint64(vint), 1)
+	} else if vflt, ok := val.(float64); ok {
+		if vflt > math.MaxUint8 || vflt < 0 || vflt != math.Trunc(vflt) {
+			so.err = fmt.Errorf("Value out of bounds %v", vflt)
+			return
+		}
+		so.appendUint(uint64(vflt), 1)
 	} else {
 		so.err = fmt.Errorf("Serialize int8 type error %T, %v", val, val)
 		return
@@ -139,19 +148,25 @@ func (serInt8 SerializedInt8) Serialize(so *Serializer, val interface{}, noMarke
 
 //Parse int8
 func (serInt8 SerializedInt8) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return so.readByte()
 }
 
 //Serialize int16
 func (serInt16 SerializedInt16) Serialize(so *Serializer, val interface{}, noMarker bool) {
 	if vuint16, ok := val.(uint16); ok {
-		so.Append(jtUtils.GetBytes(vuint16))
+		so.appendUint(uint64(vuint16), 2)
 	} else if vint, ok := val.(int); ok {
 		if vint > math.MaxUint16 || vint < 0 {
 			so.err = fmt.Errorf("Value out of bounds %d", vint)
 			return
 		}
-		so.Append(jtUtils.GetBytes(uint16(vint)))
+		so.appendUint(uint64(vint), 2)
+	} else if vflt, ok := val.(float64); ok {
+		if vflt > math.MaxUint16 || vflt < 0 || vflt != math.Trunc(vflt) {
+			so.err = fmt.Errorf("Value out of bounds %v", vflt)
+			return
+		}
+		so.appendUint(uint64(vflt), 2)
 	} else {
 		so.err = fmt.Errorf("Serialize int16 type error %T, %v", val, val)
 		return
@@ -160,19 +175,29 @@ func (serInt16 SerializedInt16) Serialize(so *Serializer, val interface{}, noMar
 
 //Parse int16
 func (serInt16 SerializedInt16) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	b := so.read(2)
+	if b == nil {
+		return nil
+	}
+	return binary.BigEndian.Uint16(b)
 }
 
 //Serialize int32
 func (serInt32 SerializedInt32) Serialize(so *Serializer, val interface{}, noMarker bool) {
 	if vuint32, ok := val.(uint32); ok {
-		so.Append(jtUtils.GetBytes(vuint32))
+		so.appendUint(uint64(vuint32), 4)
 	} else if vint, ok := val.(int); ok {
 		if vint > math.MaxUint32 || vint < 0 {
 			so.err = fmt.Errorf("Value out of bounds %d", vint)
 			return
 		}
-		so.Append(jtUtils.GetBytes(uint32(vint)))
+		so.appendUint(uint64(vint), 4)
+	} else if vflt, ok := val.(float64); ok {
+		if vflt > math.MaxUint32 || vflt < 0 || vflt != math.Trunc(vflt) {
+			so.err = fmt.Errorf("Value out of bounds %v", vflt)
+			return
+		}
+		so.appendUint(uint64(vflt), 4)
 	} else {
 		so.err = fmt.Errorf("Serialize int16 type error %T, %v", val, val)
 		return
@@ -181,13 +206,17 @@ func (serInt32 SerializedInt32) Serialize(so *Serializer, val interface{}, noMar
 
 //Parse int32
 func (serInt32 SerializedInt32) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	b := so.read(4)
+	if b == nil {
+		return nil
+	}
+	return binary.BigEndian.Uint32(b)
 }
 
 //Serialize int64
 func (serInt64 SerializedInt64) Serialize(so *Serializer, val interface{}, noMarker bool) {
 	if number, ok := val.(uint64); ok {
-		so.Append(jtUtils.GetBytes(number))
+		so.appendUint(number, 8)
 		return
 	}
 
@@ -202,75 +231,44 @@ func (serInt64 SerializedInt64) Serialize(so *Serializer, val interface{}, noMar
 			return
 		}
 
-		b := bytes.NewBufferString("")
-
-		for b.Len() < 16-len(str) {
-			b.WriteString("0")
-		}
-
-		b.WriteString(str)
-
-		SerializeHex(so, b.String(), true)
+		SerializeHex(so, strings.Repeat("0", 16-len(str))+str, true)
 		return
 	}
 
 	so.err = fmt.Errorf("Invalid type for Int64 %T, %v", val, val)
 }
 
-//Parse int64
+//Parse int64，以16位16进制字符串表示
 func (serInt64 SerializedInt64) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return parseHex(so, 8)
 }
 
-//Parse memo
+//Parse memo，MemoType、MemoData、MemoFormat 保留16进制，与底层返回的 tx_json 一致
 func (serMemo SerializedMemo) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return STObject.parse(so, false)
 }
 
 //Serialize memo
 func (serMemo SerializedMemo) Serialize(so *Serializer, val interface{}, noMarker bool) {
 	memo, ok := val.(*MemoDataInfo)
-	if !ok {
+	if !ok || memo == nil {
 		so.err = fmt.Errorf("Serialize Memo type error %T", val)
 		return
 	}
 
-	fileds := jtUtils.GetFieldNames(val)
-	for i := 0; i < len(fileds); i++ {
-		_, ok := constant.InverseFieldsMap[fileds[i]]
-		if !ok {
-			so.err = fmt.Errorf("JSON contains unknown field : %s", fileds[i])
-			return
-		}
-	}
-
-	jtUtils.SortByFieldName(fileds)
-
-	isJSON := memo.MemoFormat == "json"
-
-	for _, fn := range fileds {
-		value := jtUtils.GetFieldValue(val, fn)
-		if value == nil || value == "" {
+	//按字段编码顺序：MemoType、MemoData、MemoFormat，原文转成16进制
+	for _, field := range [...]struct {
+		name  string
+		value string
+	}{{"MemoType", memo.MemoType}, {"MemoData", memo.MemoData}, {"MemoFormat", memo.MemoFormat}} {
+		if field.value == "" {
 			continue
 		}
-		switch fn {
-		case "MemoType", "MemoFormat":
-			value = jtUtils.StringToHex(value.(string))
-		case "MemoData":
-			if _, ok := value.(string); ok {
-				value = jtUtils.StringToHex(value.(string))
-				break
-			}
-			if isJSON {
-				mjson, _ := json.Marshal(value)
-				value = jtUtils.StringToHex(string(mjson))
-				break
-			}
-			so.err = fmt.Errorf("MemoData can only be a JSON object with a valid json MemoFormat. %v. %T", value, value)
+
+		Serialize(so, field.name, jtUtils.StringToHex(field.value))
+		if so.err != nil {
 			return
 		}
-
-		Serialize(so, fn, value)
 	}
 
 	if !noMarker {
@@ -280,27 +278,20 @@ func (serMemo SerializedMemo) Serialize(so *Serializer, val interface{}, noMarke
 
 //Parse arg
 func (serArg SerializedArg) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return STObject.parse(so, false)
 }
 
 //Serialize arg
 func (serArg SerializedArg) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	fileds := jtUtils.GetFieldNames(val)
-	for i := 0; i < len(fileds); i++ {
-		kvp := constant.InverseFieldsMap[fileds[i]]
-		if kvp == nil {
-			so.err = fmt.Errorf("JSON contains unknown field %s", fileds[i])
-			return
-		}
+	param, ok := val.(*ParameterInfo)
+	if !ok || param == nil {
+		so.err = fmt.Errorf("Serialize Arg type error %T", val)
+		return
 	}
-	jtUtils.SortByFieldName(fileds)
 
-	for _, fn := range fileds {
-		if fn == "Parameter" {
-			break
-		}
-		value := jtUtils.GetFieldValue(val, fn)
-		Serialize(so, fn, value)
+	Serialize(so, "Parameter", param.Parameter)
+	if so.err != nil {
+		return
 	}
 
 	if !noMarker {
@@ -310,7 +301,7 @@ func (serArg SerializedArg) Serialize(so *Serializer, val interface{}, noMarker
 
 //Parse Hash128反序列化。
 func (serHash128 SerializedHash128) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return parseHex(so, 16)
 }
 
 //Serialize Hash128序列化。
@@ -319,29 +310,75 @@ func (serHash128 SerializedHash128) Serialize(so *Serializer, val interface{}, n
 		SerializeHex(so, v, true)
 		return
 	}
+
+	so.err = fmt.Errorf("Invalid Hash128 %v", val)
 }
 
 //Parse Hash256反序列化。
 func (serHash256 SerializedHash256) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return parseHex(so, 32)
 }
 
 //Serialize Hash256序列化。
 func (serHash256 SerializedHash256) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	if v, ok := val.(string); ok && jtUtils.MatchString("^[0-9A-F]{0,32}$", v) && len(v) <= 64 {
+	if v, ok := val.(string); ok && jtUtils.MatchString("^[0-9A-F]{0,64}$", v) && len(v) <= 64 {
 		SerializeHex(so, v, true)
 		return
 	}
+
+	so.err = fmt.Errorf("Invalid Hash256 %v", val)
 }
 
-//Parse 金额反序列化。
+//Parse 金额反序列化。SWT 返回 drops 字符串，其他货币返回 constant.Amount
 func (serAmount SerializedAmount) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	valueBytes := so.read(8)
+	if valueBytes == nil {
+		return nil
+	}
+
+	isNegative := valueBytes[0]&0x40 == 0
+
+	if valueBytes[0]&0x80 == 0 {
+		//SWT: 第二位为符号位，其余62位为 drops
+		intBytes := make([]byte, 8)
+		copy(intBytes, valueBytes)
+		intBytes[0] &= 0x3f
+		value := new(big.Int).SetBytes(intBytes)
+		if isNegative && value.Sign() != 0 {
+			return "-" + value.String()
+		}
+		return value.String()
+	}
+
+	//非SWT: 第二位为符号位，接下来8位为指数，其余54位为尾数
+	offset := int(valueBytes[0]&0x3f)<<2 + int(valueBytes[1]>>6) - 97
+	mantissaBytes := make([]byte, 7)
+	copy(mantissaBytes, valueBytes[1:])
+	mantissaBytes[0] &= 0x3f
+	mantissa := new(big.Int).SetBytes(mantissaBytes)
+
+	currency := currencyFromBytes(so.read(20))
+	issuerBytes := so.read(20)
+	if so.err != nil {
+		return nil
+	}
+
+	amount := constant.Amount{}
+	amount.Currency = currency
+	amount.Issuer = jtUtils.EncodeB58(constant.AccountPrefix, issuerBytes)
+	amount.Value = decimalString(mantissa, offset, isNegative)
+	return amount
 }
 
 //Serialize 金额序列化。
 func (serAmount SerializedAmount) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	tumAmount, err := fromJSON(val)
+	var tumAmount *TumAmount
+	var err error
+	if drops, ok := val.(string); ok && so.dropsAmount {
+		tumAmount, err = fromDrops(drops)
+	} else {
+		tumAmount, err = fromJSON(val)
+	}
 	if err != nil {
 		so.err = err
 		return
@@ -352,84 +389,35 @@ func (serAmount SerializedAmount) Serialize(so *Serializer, val interface{}, noM
 	}
 
 	if tumAmount.IsNative {
-		valueHex := hex.EncodeToString(tumAmount.Value.Bytes())
-
-		if len(valueHex) > 16 {
+		//第一位：本币；第二位：非负；其余62位为 drops
+		if tumAmount.Value.BitLen() > 62 {
 			so.err = fmt.Errorf("Amount value out of bounds")
-		}
-		b := bytes.NewBufferString("")
-		for b.Len() < 16-len(valueHex) {
-			b.WriteString("0")
-		}
-		b.WriteString(valueHex)
-
-		valueBytes, err := jtUtils.HexToBytes(b.String())
-		if err != nil {
-			so.err = fmt.Errorf("Hex to bytes error %s", b.String())
 			return
 		}
 
-		valueBytes[0] &= 0x3f
-
+		value := tumAmount.Value.Uint64()
 		if !tumAmount.IsNegative {
-			valueBytes[0] |= 0x40
+			value |= 1 << 62
 		}
-		so.Append(valueBytes)
+		so.appendUint(value, 8)
 	} else {
 		//For other non-native currency
-		//1. Serialize the currency value with offset
-		//Put offset
-		var hi, lo int = 0, 0
-		hi |= 1 << 31
-		hi = int(int32(hi))
-
+		//第一位：非本币；第二位：非负；8 位 offset（加 97）；54 位 mantissa。零值只有第一位
+		value := uint64(1) << 63
 		if !tumAmount.IsZeroM() {
-			// Second bit: non-negative?
-			if !tumAmount.IsNegative {
-				hi |= 1 << 30
-				hi = int(int32(hi))
-			}
-			// Next eight bits: offset/exponent
-			hi |= ((97 + tumAmount.Offset) & 0xff) << 22
-			hi = int(int32(hi))
-			// Remaining 54 bits: mantissa
-			hi |= (int(tumAmount.Value.Int64()) >> 32) & 0x3fffff
-			hi = int(int32(hi))
-			lo = int(tumAmount.Value.Int64()) & 0xffffffff
-		}
-
-		// Convert from a bitArray to an array of bytes.
-		arr := []int{hi, lo}
-		l := len(arr)
-		var bl int64
-		if l == 0 {
-			bl = 0
-		} else {
-			x := arr[l-1]
-			roundX := x / 0x10000000000
-			if roundX == 0 {
-				roundX = 32
+			if tumAmount.Value.BitLen() > 54 || tumAmount.Offset < minIOUOffset || tumAmount.Offset > maxIOUOffset {
+				so.err = fmt.Errorf("Amount value out of range")
+				return
 			}
-			bl = int64((l-1)*32) + int64(roundX)
-		}
 
-		var tmparray []byte
-		var tmp int
-		for i := 0; int64(i) < int64(bl/8); i++ {
-			if (i & 3) == 0 {
-				tmp = arr[i/4]
+			if !tumAmount.IsNegative {
+				value |= 1 << 62
 			}
-
-			tmparray = append(tmparray, byte(tmp>>24))
-			tmp <<= 8
+			value |= uint64(97+tumAmount.Offset) << 54
+			value |= tumAmount.Value.Uint64()
 		}
 
-		if len(tmparray) > 8 {
-			so.err = fmt.Errorf("Invalid byte array length in AMOUNT value representation")
-			return
-		}
-
-		so.Append(tmparray)
+		so.appendUint(value, 8)
 		tumBytes, err := tumAmount.TumToBytes()
 		if err != nil {
 			so.err = err
@@ -447,53 +435,124 @@ func (serAmount SerializedAmount) Serialize(so *Serializer, val interface{}, noM
 
 //Parse currency
 func (serCurrency SerializedCurrency) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	b := so.read(20)
+	if b == nil {
+		return nil
+	}
+	return currencyFromBytes(b)
+}
+
+//currencyFromBytes 20字节货币编码转成货币名称，全零为SWT，非标准货币返回40位16进制
+func currencyFromBytes(b []byte) string {
+	if len(b) != 20 {
+		return ""
+	}
+
+	isZero := true
+	for _, v := range b {
+		if v != 0 {
+			isZero = false
+			break
+		}
+	}
+
+	if isZero {
+		return nativeCurrency()
+	}
+
+	//3到6位货币名称放在第9到14字节，其余字节为零
+	if bytes.Count(b[:9], []byte{0}) == 9 && bytes.Count(b[15:], []byte{0}) == 5 {
+		code := string(bytes.TrimLeft(b[9:15], "\x00"))
+		if len(code) >= currencyNameLen && len(code) <= currencyNameLen2 && jtUtils.IsValidCurrency(code) {
+			return code
+		}
+	}
+
+	return strings.ToUpper(hex.EncodeToString(b))
 }
 
 //Serialize currency
 func (serCurrency SerializedCurrency) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	currencty := val.(string)
-	so.Append(serCurrency.fromJSONToBytes(currencty))
+	currencty, ok := val.(string)
+	if !ok {
+		so.err = fmt.Errorf("Serialize currency type error %T", val)
+		return
+	}
+
+	result, err := serCurrency.fromJSONToBytes(currencty)
+	if err != nil {
+		so.err = err
+		return
+	}
+	so.Append(result)
 }
 
-func (serCurrency SerializedCurrency) fromJSONToBytes(currencty string) []byte {
-	var result []byte
-	if currencty != "" {
-		if jtUtils.IsHexString(currencty) && len(currencty) == 40 {
-			var err error
-			result, err = jtUtils.HexToBytes(currencty)
+//fromJSONToBytes 货币名称转成20字节编码，3到6位名称放在第9到14字节，40位16进制直接解码
+func (serCurrency SerializedCurrency) fromJSONToBytes(currencty string) ([]byte, error) {
+	if len(currencty) == 40 && jtUtils.IsHexString(currencty) {
+		return jtUtils.HexToBytes(currencty)
+	}
 
-			if err != nil {
-				panic("Invalid currencty.")
-			}
+	if len(currencty) < currencyNameLen || len(currencty) > currencyNameLen2 || !jtUtils.IsValidCurrency(currencty) {
+		return nil, fmt.Errorf("Input tum code invalid %v", currencty)
+	}
 
-		} else if jtUtils.IsValidCurrency(currencty) {
-			if len(currencty) >= currencyNameLen && len(currencty) <= currencyNameLen2 {
-				var end = 14
-				var clen = len(currencty) - 1
-				for x := clen; x >= 0; x-- {
-					result[end-x] = byte(currencty[clen-x] & 0xff)
-				}
-			}
-		} else {
-			panic(fmt.Sprintf("Input tum code invalid %v", currencty))
-		}
-	} else {
-		panic(fmt.Sprintf("Input tum code invalid %v", currencty))
+	result := make([]byte, 20)
+	if currencty == nativeCurrency() {
+		return result, nil
 	}
 
-	return result
+	copy(result[15-len(currencty):15], currencty)
+	return result, nil
 }
 
 //Parse object
 func (serObject SerializedObject) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return serObject.parse(so, false)
+}
+
+//parse 逐个读取字段直至对象结束标志 0xe1，noMarker 为 true 时读取至 Buffer 结束
+func (serObject SerializedObject) parse(so *Serializer, noMarker bool) map[string]interface{} {
+	txData := make(map[string]interface{})
+	for so.err == nil {
+		b, ok := so.peek()
+		if !ok {
+			if !noMarker {
+				so.err = fmt.Errorf("Object end marker not found")
+			}
+			break
+		}
+
+		if !noMarker && b == 0xe1 {
+			so.readByte()
+			break
+		}
+
+		field, value := Parse(so)
+		if so.err != nil {
+			break
+		}
+		txData[field] = value
+	}
+
+	if so.err != nil {
+		return nil
+	}
+
+	return txData
+}
+
+//objectField 待序列化的字段及其编码，用于排序
+type objectField struct {
+	name        string
+	coordinates *constant.KeyValuePair
 }
 
 //Serialize object
 func (serObject SerializedObject) Serialize(so *Serializer, val interface{}, noMarker bool) {
 	txData, ok := val.(map[string]interface{})
 	if !ok {
+		//其他结构体经 JSON 转成 map，较慢，仅作兼容
 		bytes, err := json.Marshal(val)
 		if err != nil {
 			so.err = fmt.Errorf("Serialive object type must be map[string]interface{}. Actual type : %T. Value : %v", val, val)
@@ -507,36 +566,27 @@ func (serObject SerializedObject) Serialize(so *Serializer, val interface{}, noM
 		}
 	}
 
-	var fieldNames []string
-	for k := range txData {
-		_, ok := constant.InverseFieldsMap[k]
+	var buf [32]objectField
+	fields := buf[:0]
+	for k, value := range txData {
+		//小写开头的字段（如 index、hash、delivered_amount）是底层附加的信息，不参与序列化
+		if k == "" || unicode.IsLower(rune(k[0])) || value == nil {
+			continue
+		}
+
+		coordinates, ok := constant.InverseFieldsMap[k]
 		if !ok {
 			so.err = fmt.Errorf("Not fund field name %s", k)
 			return
 		}
 
-		fieldNames = append(fieldNames, k)
+		fields = append(fields, objectField{k, coordinates})
 	}
 
-	jtUtils.SortByFieldName(fieldNames)
-
-	for _, field := range fieldNames {
-		value := txData[field]
-		if value == nil {
-			continue
-		}
-
-		if txType, ok := value.(string); ok && field == "TransactionType" {
-			txint, err := strconv.ParseUint(txType, 10, 16)
-			if err != nil {
-				so.err = err
-				return
-			}
-			Serialize(so, field, uint16(txint))
-		} else {
-			Serialize(so, field, value)
-		}
+	sortObjectFields(fields)
 
+	for _, field := range fields {
+		serializeField(so, field.name, field.coordinates, txData[field.name])
 		if so.err != nil {
 			return
 		}
@@ -547,13 +597,58 @@ func (serObject SerializedObject) Serialize(so *Serializer, val interface{}, noM
 	}
 }
 
-//Parse array
+//sortObjectFields 按类型编码、字段编码排序；字段数量很少，插入排序即可
+func sortObjectFields(fields []objectField) {
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fieldLess(fields[j].coordinates, fields[j-1].coordinates); j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+}
+
+func fieldLess(x, y *constant.KeyValuePair) bool {
+	if x.Key != y.Key {
+		return x.Key < y.Key
+	}
+	return x.Value < y.Value
+}
+
+//Parse array，每个元素都是单个字段的对象
 func (serArray SerializedArray) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	array := make([]interface{}, 0)
+	for so.err == nil {
+		b, ok := so.peek()
+		if !ok {
+			so.err = fmt.Errorf("Array end marker not found")
+			break
+		}
+
+		if b == 0xf1 {
+			so.readByte()
+			break
+		}
+
+		field, value := Parse(so)
+		if so.err != nil {
+			break
+		}
+		array = append(array, map[string]interface{}{field: value})
+	}
+
+	if so.err != nil {
+		return nil
+	}
+
+	return array
 }
 
 //Serialize array
 func (serArray SerializedArray) Serialize(so *Serializer, val interface{}, noMarker bool) {
+	if items, ok := val.([]interface{}); ok {
+		serArray.serializeSlice(so, items)
+		return
+	}
+
 	array, ok := val.(*list.List)
 	if !ok {
 		so.err = fmt.Errorf("Serialize array type error %T. Value : %v", val, val)
@@ -561,16 +656,47 @@ func (serArray SerializedArray) Serialize(so *Serializer, val interface{}, noMar
 	}
 
 	for e := array.Front(); e != nil; e = e.Next() {
-		fields := jtUtils.GetFieldNames(e.Value)
+		switch item := e.Value.(type) {
+		case *MemoInfo:
+			Serialize(so, "Memo", item.Memo)
+		case *ArgInfo:
+			Serialize(so, "Arg", item.Arg)
+		case map[string]interface{}:
+			if len(item) != 1 {
+				so.err = fmt.Errorf("Cannot serialize an array containing non-single-key objects")
+				return
+			}
+			for field, value := range item {
+				Serialize(so, field, value)
+			}
+		default:
+			so.err = fmt.Errorf("Serialize array item type error %T", e.Value)
+		}
+
+		if so.err != nil {
+			return
+		}
+	}
+
+	STInt8.Serialize(so, uint8(0xf1), false)
+}
 
-		if len(fields) != 1 {
+//serializeSlice 序列化 JSON 数组，如底层返回的 AffectedNodes
+func (serArray SerializedArray) serializeSlice(so *Serializer, items []interface{}) {
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok || len(itemMap) != 1 {
 			so.err = fmt.Errorf("Cannot serialize an array containing non-single-key objects")
 			return
 		}
 
-		value := jtUtils.GetFieldValue(e.Value, fields[0])
+		for field, value := range itemMap {
+			Serialize(so, field, value)
+		}
 
-		Serialize(so, fields[0], value)
+		if so.err != nil {
+			return
+		}
 	}
 
 	STInt8.Serialize(so, uint8(0xf1), false)
@@ -578,23 +704,78 @@ func (serArray SerializedArray) Serialize(so *Serializer, val interface{}, noMar
 
 //Parse hash 160
 func (serHash160 SerializedHash160) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	return parseHex(so, 20)
 }
 
 //Serialize hash 160
 func (serHash160 SerializedHash160) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	valStr := val.(string)
+	valStr, ok := val.(string)
+	if !ok || len(valStr) != 40 {
+		so.err = fmt.Errorf("Invalid Hash160 %v", val)
+		return
+	}
 	SerializeHex(so, valStr, true)
 }
 
 //Parse path set
 func (serPathSet SerializedPathSet) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	var pathSet [][]PathComputed
+	var path []PathComputed
+	for so.err == nil {
+		typev := int(so.readByte())
+		if so.err != nil {
+			break
+		}
+
+		if typev == typeEnd {
+			pathSet = append(pathSet, path)
+			break
+		}
+
+		if typev == typeBoundary {
+			pathSet = append(pathSet, path)
+			path = nil
+			continue
+		}
+
+		entry := PathComputed{Type: typev, TypeHex: fmt.Sprintf("%016X", typev)}
+		if typev&typeAccount != 0 {
+			entry.Account = jtUtils.EncodeB58(constant.AccountPrefix, so.read(20))
+		}
+
+		if typev&typeCurrency != 0 {
+			entry.Currency = currencyFromBytes(so.read(20))
+		}
+
+		if typev&typeIssuer != 0 {
+			entry.Issuer = jtUtils.EncodeB58(constant.AccountPrefix, so.read(20))
+		}
+		path = append(path, entry)
+	}
+
+	if so.err != nil {
+		return nil
+	}
+
+	return pathSet
 }
 
 //Serialize path set
 func (serPathSet SerializedPathSet) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	path := val.([][]PathComputed)
+	var path [][]PathComputed
+	switch v := val.(type) {
+	case [][]PathComputed:
+		path = v
+	case []interface{}:
+		var err error
+		if path, err = pathSetFromJSON(v); err != nil {
+			so.err = err
+			return
+		}
+	default:
+		so.err = fmt.Errorf("Serialize path set type error %T", val)
+		return
+	}
 	for i := 0; i < len(path); i++ {
 		if i > 0 {
 			STInt8.Serialize(so, typeBoundary, false)
@@ -628,8 +809,12 @@ func (serPathSet SerializedPathSet) Serialize(so *Serializer, val interface{}, n
 			}
 
 			if entry.Currency != "" {
-				sc := new(SerializedCurrency)
-				so.Append(sc.fromJSONToBytes(entry.Currency))
+				currency, err := new(SerializedCurrency).fromJSONToBytes(entry.Currency)
+				if err != nil {
+					so.err = err
+					return
+				}
+				so.Append(currency)
 			}
 
 			if entry.Issuer != "" {
@@ -646,14 +831,77 @@ func (serPathSet SerializedPathSet) Serialize(so *Serializer, val interface{}, n
 	STInt8.Serialize(so, uint8(typeEnd), false)
 }
 
+//pathSetFromJSON 转换 JSON 格式的路径，如底层返回的 tx_json 中的 Paths
+func pathSetFromJSON(items []interface{}) ([][]PathComputed, error) {
+	pathSet := make([][]PathComputed, 0, len(items))
+	for _, item := range items {
+		steps, ok := item.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Serialize path type error %T", item)
+		}
+
+		path := make([]PathComputed, 0, len(steps))
+		for _, step := range steps {
+			stepMap, ok := step.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Serialize path step type error %T", step)
+			}
+
+			entry := PathComputed{}
+			entry.Account, _ = stepMap["account"].(string)
+			entry.Currency, _ = stepMap["currency"].(string)
+			entry.Issuer, _ = stepMap["issuer"].(string)
+			path = append(path, entry)
+		}
+		pathSet = append(pathSet, path)
+	}
+
+	return pathSet, nil
+}
+
 //Parse Vector 256 反序列化。
 func (serVector256 SerializedVector256) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	length := int(ParseVarint(so))
+	if so.err != nil {
+		return nil
+	}
+
+	if length%32 != 0 {
+		so.err = fmt.Errorf("Invalid Vector256 length %d", length)
+		return nil
+	}
+
+	array := make([]string, 0, length/32)
+	for i := 0; i < length/32; i++ {
+		hash, ok := STHash256.Parse(so).(string)
+		if !ok {
+			return nil
+		}
+		array = append(array, hash)
+	}
+
+	return array
 }
 
 //Serialize Vector 256 序列化。
 func (serVector256 SerializedVector256) Serialize(so *Serializer, val interface{}, noMarker bool) {
-	array := val.([]string)
+	var array []string
+	switch v := val.(type) {
+	case []string:
+		array = v
+	case []interface{}:
+		for _, item := range v {
+			hash, ok := item.(string)
+			if !ok {
+				so.err = fmt.Errorf("Serialize Vector256 item type error %T", item)
+				return
+			}
+			array = append(array, hash)
+		}
+	default:
+		so.err = fmt.Errorf("Serialize Vector256 type error %T", val)
+		return
+	}
 	SerializeVarint(so, uint(len(array)*32))
 
 	for _, v := range array {
@@ -663,7 +911,12 @@ func (serVector256 SerializedVector256) Serialize(so *Serializer, val interface{
 
 //Parse variable length 反序列化。
 func (serVL SerializedVariableLength) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	length := int(ParseVarint(so))
+	if so.err != nil {
+		return nil
+	}
+
+	return parseHex(so, length)
 }
 
 //Serialize variable length 序列化。
@@ -678,7 +931,17 @@ func (serVL SerializedVariableLength) Serialize(so *Serializer, val interface{},
 
 //Parse 账号反序列化。
 func (serAccount SerializedAccount) Parse(so *Serializer) interface{} {
-	return fmt.Errorf("Not implemented error")
+	length := int(ParseVarint(so))
+	if so.err != nil {
+		return nil
+	}
+
+	if length != 20 {
+		so.err = fmt.Errorf("Invalid account length %d", length)
+		return nil
+	}
+
+	return jtUtils.EncodeB58(constant.AccountPrefix, so.read(length))
 }
 
 //Serialize 账号序列化。
@@ -696,3 +959,13 @@ func (serAccount SerializedAccount) Serialize(so *Serializer, val interface{}, n
 	SerializeVarint(so, uint(len(addrByte)))
 	so.Append(addrByte)
 }
+
+//parseHex 读取 n 个字节并转成大写16进制字符串
+func parseHex(so *Serializer, n int) interface{} {
+	b := so.read(n)
+	if b == nil {
+		return nil
+	}
+
+	return strings.ToUpper(hex.EncodeToString(b))
+}
@@ -14,16 +14,55 @@ package serializer
 
 import (
 	"encoding/hex"
+	"container/list"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"unicode"
 
+	"jingtumlib/constant"
 	jtUtils "jingtumlib/utils"
 )
 
 //Serializer struct
 type Serializer struct {
-	Buffer []byte
-	err    error
+	Buffer  []byte
+	err     error
+	pointer int
+	//dropsAmount 字符串形式的 SWT 金额是否以 drops 表示（底层返回的账本对象、交易元数据）
+	dropsAmount bool
+	//pooled 是否取自 serializerPool，只有这样的 Serializer 才能 Release
+	pooled bool
+}
+
+//maxPooledBuffer 超过该容量的 Buffer 不放回池中，避免个别大对象长期占用内存
+const maxPooledBuffer = 64 * 1024
+
+//serializerPool 复用 Serializer 及其 Buffer
+var serializerPool = sync.Pool{
+	New: func() interface{} {
+		return &Serializer{Buffer: make([]byte, 0, 512)}
+	},
+}
+
+//newSerializer 从池中取出一个空的 Serializer
+func newSerializer() *Serializer {
+	so := serializerPool.Get().(*Serializer)
+	so.pooled = true
+	return so
+}
+
+//Release 将 FromJSON、FromTx 返回的 Serializer 放回池中复用。
+//调用后不能再使用该 Serializer 及其 Buffer；不调用则由 GC 回收。
+func (so *Serializer) Release() {
+	if so == nil || !so.pooled || cap(so.Buffer) > maxPooledBuffer {
+		return
+	}
+
+	*so = Serializer{Buffer: so.Buffer[:0]}
+	serializerPool.Put(so)
 }
 
 //SerializedInt8 int8
@@ -36,19 +75,19 @@ var (
 	defaultv = 2
 
 	//交易类型
-	transactionTypeAccountSet     = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"EmailHash", optional}, {"WalletLocator", optional}, {"WalletSize", optional}, {"MessageKey", optional}, {"Domain", optional}, {"TransferRate", optional}}
-	transactionTypeTrustSet       = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"LimitAmount", optional}, {"QualityIn", optional}, {"QualityOut", optional}}
-	transactionTypeOfferCreate    = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TakerPays", required}, {"TakerGets", required}, {"Expiration", optional}}
-	transactionTypeOfferCancel    = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"OfferSequence", required}}
-	transactionTypeSetRegularKey  = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"RegularKey", required}}
-	transactionTypePayment        = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Destination", required}, {"Amount", required}, {"SendMax", optional}, {"Paths", defaultv}, {"InvoiceID", optional}, {"DestinationTag", optional}}
-	transactionTypeContract       = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Expiration", required}, {"BondAmount", required}, {"StampEscrow", required}, {"JingtumEscrow", required}, {"CreateCode", optional}, {"FundCode", optional}, {"RemoveCode", optional}, {"ExpireCode", optional}}
-	transactionTypeRemoveContract = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Target", required}}
-	transactionTypeEnableFeature  = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Feature", required}}
-	transactionTypeSetFee         = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Features", required}, {"BaseFee", required}, {"ReferenceFeeUnits", required}, {"ReserveBase", required}, {"ReserveIncrement", required}}
-	transactionTypeConfigContract = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Method", required}, {"Payload", optional}, {"Destination", optional}, {"Amount", optional}, {"Contracttype", optional}, {"ContractMethod", optional}, {"Args", optional}}
-	transactionTypeRelationSet = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Method", required}, {"Payload", optional}, {"Destination", optional}, {"Amount", optional}, {"Contracttype", optional}, {"ContractMethod", optional}, {"Args", optional}}
-	transactionTypeRelationDel = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"Method", required}, {"Payload", optional}, {"Destination", optional}, {"Amount", optional}, {"Contracttype", optional}, {"ContractMethod", optional}, {"Args", optional}}
+	transactionTypeAccountSet     = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"EmailHash", optional}, {"WalletLocator", optional}, {"WalletSize", optional}, {"MessageKey", optional}, {"Domain", optional}, {"TransferRate", optional}, {"SetFlag", optional}, {"ClearFlag", optional}}
+	transactionTypeTrustSet       = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"LimitAmount", optional}, {"QualityIn", optional}, {"QualityOut", optional}}
+	transactionTypeOfferCreate    = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"TakerPays", required}, {"TakerGets", required}, {"Expiration", optional}}
+	transactionTypeOfferCancel    = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"OfferSequence", required}}
+	transactionTypeSetRegularKey  = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"RegularKey", required}}
+	transactionTypePayment        = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Destination", required}, {"Amount", required}, {"SendMax", optional}, {"Paths", defaultv}, {"InvoiceID", optional}, {"DestinationTag", optional}}
+	transactionTypeContract       = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Expiration", required}, {"BondAmount", required}, {"StampEscrow", required}, {"JingtumEscrow", required}, {"CreateCode", optional}, {"FundCode", optional}, {"RemoveCode", optional}, {"ExpireCode", optional}}
+	transactionTypeRemoveContract = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Target", required}}
+	transactionTypeEnableFeature  = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Feature", required}}
+	transactionTypeSetFee         = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Features", required}, {"BaseFee", required}, {"ReferenceFeeUnits", required}, {"ReserveBase", required}, {"ReserveIncrement", required}}
+	transactionTypeConfigContract = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Method", required}, {"Payload", optional}, {"Destination", optional}, {"Amount", optional}, {"Contracttype", optional}, {"ContractMethod", optional}, {"Args", optional}}
+	transactionTypeRelationSet = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Target", required}, {"RelationType", required}, {"LimitAmount", required}}
+	transactionTypeRelationDel = [][]interface{}{{"TransactionType", required}, {"Flags", optional}, {"SourceTag", optional}, {"LastLedgerSequence", optional}, {"Account", required}, {"Sequence", optional}, {"Fee", required}, {"OperationLimit", optional}, {"SigningPubKey", optional}, {"TxnSignature", optional}, {"TxnSignatures", optional}, {"Memos", optional}, {"Target", required}, {"RelationType", required}, {"LimitAmount", required}}
 	transactionTypes              = map[uint8][][]interface{}{3: transactionTypeAccountSet, 20: transactionTypeTrustSet, 7: transactionTypeOfferCreate, 8: transactionTypeOfferCancel, 5: transactionTypeSetRegularKey, 0: transactionTypePayment, 9: transactionTypeContract, 10: transactionTypeRemoveContract, 100: transactionTypeEnableFeature, 101: transactionTypeSetFee, 30: transactionTypeConfigContract, 21: transactionTypeRelationSet, 22:transactionTypeRelationDel}
 	txTypeStrMapNumber            = map[string]uint8{"AccountSet": 3, "TrustSet": 20, "OfferCreate": 7, "OfferCancel": 8, "SetRegularKey": 5, "Payment": 0, "Contract": 9, "RemoveContract": 10, "EnableFeature": 100, "SetFee": 101, "ConfigContract": 30, "RelationSet": 21, "RelationDel": 22}
 
@@ -64,7 +103,7 @@ var (
 	ledgerEntryTypeSkyWellState    = [][]interface{}{{"LedgerIndex", optional}, {"LedgerEntryType", required}, {"Flags", required}, {"LedgerEntryType", required}, {"Flags", required}, {"PreviousTxnLgrSeq", required}, {"HighQualityIn", optional}, {"HighQualityOut", optional}, {"LowQualityIn", optional}, {"LowQualityOut", optional}, {"LowNode", optional}, {"HighNode", optional}, {"PreviousTxnID", required}, {"LedgerIndex", optional}, {"Balance", required}, {"LowLimit", required}, {"HighLimit", required}}
 	ledgerEntryTypes               = map[uint8][][]interface{}{97: ledgerEntryTypeAccountRoot, 99: ledgerEntryTypeContract, 100: ledgerEntryTypeDirectoryNode, 102: ledgerEntryTypeEnabledFeatures, 115: ledgerEntryTypeFeeSettings, 103: ledgerEntryTypeGeneratorMap, 104: ledgerEntryTypeLedgerHashes, 110: ledgerEntryTypeNickName, 111: ledgerEntryTypeOffer, 114: ledgerEntryTypeSkyWellState}
 
-	metaData = [][]interface{}{{"TransactionIndex", required}, {"TransactionResult", required}, {"AffectedNodes", required}}
+	metaData = [][]interface{}{{"TransactionIndex", required}, {"TransactionResult", required}, {"AffectedNodes", required}, {"DeliveredAmount", optional}}
 )
 
 //MemoInfo 备注
@@ -79,6 +118,16 @@ type MemoDataInfo struct {
 	MemoType   string
 }
 
+//ArgInfo 合约参数
+type ArgInfo struct {
+	Arg *ParameterInfo
+}
+
+//ParameterInfo 合约参数，Parameter 为16进制字符串
+type ParameterInfo struct {
+	Parameter string
+}
+
 // type TxData struct {
 // 	Flags           uint32
 // 	Fee             interface{}
@@ -97,8 +146,19 @@ type MemoDataInfo struct {
 // 	SigningPubKey   string
 // }
 
-//FromJSON 交易数据序列化。
+//FromJSON 交易、账本对象或交易元数据序列化。
+//账本对象（含 LedgerEntryType）及交易元数据（含 AffectedNodes）为底层返回的格式，SWT 金额以 drops 表示。
 func FromJSON(txData map[string]interface{}) (*Serializer, error) {
+	return serializeJSON(txData, false)
+}
+
+//FromTxJSON 按底层 tx_json 的格式序列化交易，SWT 金额以 drops 字符串表示，与 Decode 的结果一致。
+func FromTxJSON(txJSON map[string]interface{}) (*Serializer, error) {
+	return serializeJSON(txJSON, true)
+}
+
+//serializeJSON 按 TransactionType、LedgerEntryType 或 AffectedNodes 选择模板序列化，dropsAmount 表示交易的 SWT 金额是否以 drops 表示
+func serializeJSON(txData map[string]interface{}, dropsAmount bool) (*Serializer, error) {
 	var typedef [][]interface{}
 
 	txType, ok := txData["TransactionType"]
@@ -112,48 +172,324 @@ func FromJSON(txData map[string]interface{}) (*Serializer, error) {
 			}
 
 			typedef = transactionTypes[typeInt]
+		}
+	} else if entryType, ok := txData["LedgerEntryType"]; ok {
+		entryCode, err := getLedgerEntryType(entryType)
+		if err != nil {
+			return nil, err
+		}
 
-			txData["TransactionType"] = strconv.Itoa(int(typeInt))
+		code, ok := entryCode.(int)
+		if !ok {
+			return nil, fmt.Errorf("LedgerEntryType (%v) invalid", entryType)
 		}
+
+		typedef = ledgerEntryTypes[uint8(code)]
+		dropsAmount = true
+	} else if _, ok := txData["AffectedNodes"]; ok {
+		typedef = metaData
+		dropsAmount = true
 	}
 
 	if len(typedef) == 0 {
 		return nil, fmt.Errorf("Object to be serialized must contain either TransactionType, LedgerEntryType or AffectedNodes")
 	}
 
-	so := new(Serializer)
+	so := newSerializer()
+	so.dropsAmount = dropsAmount
 	so.Serialize(typedef, txData)
 
 	if so.err != nil {
-		return nil, so.err
+		err := so.err
+		so.Release()
+		return nil, err
 	}
 
 	return so, nil
 }
 
-//Serialize Object 序列化。
+//ValidationError 按类型模板校验字段的错误，列出全部缺少的必填字段及该类型不允许的字段
+type ValidationError struct {
+	//Type 交易或账本对象类型，如 Payment
+	Type       string
+	Missing    []string
+	NotAllowed []string
+}
+
+func (e *ValidationError) Error() string {
+	var problems []string
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing fields: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.NotAllowed) > 0 {
+		problems = append(problems, "fields not allowed: "+strings.Join(e.NotAllowed, ", "))
+	}
+
+	return fmt.Sprintf("%s is invalid, %s", e.Type, strings.Join(problems, "; "))
+}
+
+//Serialize Object 序列化，先按 typedef 校验字段。
 func (so *Serializer) Serialize(typedef [][]interface{}, txData map[string]interface{}) {
+	txData, err := checkFields(typedef, txData)
+	if err != nil {
+		so.err = err
+		return
+	}
+
 	STObject.Serialize(so, txData, true)
 }
 
+//checkFields 校验必填字段及不允许的字段；默认值字段（defaultv）为空时即为默认值，不参与序列化。
+//没有需要去掉的字段时直接返回 txData，不做复制
+func checkFields(typedef [][]interface{}, txData map[string]interface{}) (map[string]interface{}, error) {
+	if len(typedef) == 0 {
+		return txData, nil
+	}
+
+	var missing, notAllowed, omitted []string
+	for _, spec := range typedef {
+		field := spec[0].(string)
+		if _, ok := txData[field]; !ok && spec[1].(int) == required {
+			missing = append(missing, field)
+		}
+	}
+
+	for k, v := range txData {
+		//小写开头的字段（如 index、hash）不参与序列化，无需校验
+		if k == "" || unicode.IsLower(rune(k[0])) {
+			continue
+		}
+
+		requirement, ok := fieldRequirement(typedef, k)
+		if !ok {
+			notAllowed = append(notAllowed, k)
+			continue
+		}
+
+		if requirement == required && v == nil {
+			missing = append(missing, k)
+			continue
+		}
+
+		if requirement == defaultv && isEmptyValue(v) {
+			omitted = append(omitted, k)
+		}
+	}
+
+	if len(missing) > 0 || len(notAllowed) > 0 {
+		sort.Strings(missing)
+		sort.Strings(notAllowed)
+		return nil, &ValidationError{Type: objectName(txData), Missing: missing, NotAllowed: notAllowed}
+	}
+
+	if len(omitted) == 0 {
+		return txData, nil
+	}
+
+	fields := make(map[string]interface{}, len(txData))
+	for k, v := range txData {
+		fields[k] = v
+	}
+	for _, k := range omitted {
+		delete(fields, k)
+	}
+
+	return fields, nil
+}
+
+//fieldRequirement 字段在模板中的要求（required、optional、defaultv），模板只有十几个字段，顺序查找即可
+func fieldRequirement(typedef [][]interface{}, field string) (int, bool) {
+	for _, spec := range typedef {
+		if spec[0].(string) == field {
+			return spec[1].(int), true
+		}
+	}
+
+	return 0, false
+}
+
+//objectName 交易类型、账本对象类型或交易元数据
+func objectName(txData map[string]interface{}) string {
+	switch v := txData["TransactionType"].(type) {
+	case string:
+		if code, err := strconv.ParseUint(v, 10, 8); err == nil {
+			if name, err := getTransactionType(uint8(code)); err == nil {
+				return name.(string)
+			}
+		}
+		return v
+	case uint16:
+		if name, err := getTransactionType(uint8(v)); err == nil {
+			return name.(string)
+		}
+	}
+
+	if v, ok := txData["LedgerEntryType"].(string); ok {
+		return v
+	}
+
+	return "TransactionMetaData"
+}
+
+//isEmptyValue 空值判断，如空的 Paths
+func isEmptyValue(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case [][]PathComputed:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	case map[string]interface{}:
+		return len(value) == 0
+	case *list.List:
+		return value == nil || value.Len() == 0
+	}
+
+	return false
+}
+
 //Append Buffer append
 func (so *Serializer) Append(v []byte) {
 	so.Buffer = append(so.Buffer, v...)
 	// fmt.Println(so.Buffer)
 }
 
+//appendUint 以大端序追加 size 个字节的无符号整数
+func (so *Serializer) appendUint(v uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		so.Buffer = append(so.Buffer, byte(v>>(8*uint(i))))
+	}
+}
+
 //Hash 序列化哈希。
 func (so *Serializer) Hash(prefix uint32) []byte {
-	sotemp := new(Serializer)
-	STInt32.Serialize(sotemp, prefix, false)
-	sotemp.Buffer = append(sotemp.Buffer, so.Buffer...)
 	sh512 := jtUtils.NewSha512()
-	sh512.Add(sotemp.Buffer)
+	sh512.Add32(prefix)
+	sh512.Add(so.Buffer)
 
 	return sh512.Finish256() //jtUtils.ByteToHexString(sh512.Finish256())
 }
 
+//SigningData 签名数据，哈希前缀 + 序列化数据。secp256k1 对其 sha512 half 哈希签名，ed25519 直接签名
+func (so *Serializer) SigningData(prefix uint32) []byte {
+	data := make([]byte, 4, 4+len(so.Buffer))
+	data[0], data[1], data[2], data[3] = byte(prefix>>24), byte(prefix>>16), byte(prefix>>8), byte(prefix)
+
+	return append(data, so.Buffer...)
+}
+
+//TransactionID 计算已签名交易 blob 的交易哈希（交易 ID），与底层返回的 hash 一致。
+func TransactionID(blob string) (string, error) {
+	buffer, err := jtUtils.HexToBytes(blob)
+	if err != nil || len(buffer) == 0 {
+		return "", fmt.Errorf("Invalid hex string %s", blob)
+	}
+
+	so := new(Serializer)
+	so.Buffer = buffer
+	return strings.ToUpper(hex.EncodeToString(so.Hash(constant.HashPrefixTransactionID))), nil
+}
+
+//LedgerEntryHash 计算账本对象的哈希，即账本状态树中叶子节点的哈希。
+//对象的索引取自 index 或 LedgerIndex 字段。
+func LedgerEntryHash(entry map[string]interface{}) (string, error) {
+	index, ok := entry["index"].(string)
+	if !ok {
+		index, ok = entry["LedgerIndex"].(string)
+	}
+	if !ok {
+		return "", fmt.Errorf("Ledger entry index not found")
+	}
+
+	indexBytes, err := hex.DecodeString(index)
+	if err != nil || len(indexBytes) != 32 {
+		return "", fmt.Errorf("Invalid ledger entry index %s", index)
+	}
+
+	//LedgerIndex 是对象的键，不属于对象本身
+	entryData := make(map[string]interface{}, len(entry))
+	for k, v := range entry {
+		if k != "LedgerIndex" {
+			entryData[k] = v
+		}
+	}
+
+	so, err := FromJSON(entryData)
+	if err != nil {
+		return "", err
+	}
+
+	so.Append(indexBytes)
+	return strings.ToUpper(hex.EncodeToString(so.Hash(constant.HashPrefixLeafNode))), nil
+}
+
 //ToHex 序列化转 16 进制。
 func (so *Serializer) ToHex() string {
 	return hex.EncodeToString(so.Buffer)
 }
+
+//ToJSON 从当前位置开始反序列化，直至 Buffer 结束。
+func (so *Serializer) ToJSON() (map[string]interface{}, error) {
+	txData := STObject.parse(so, true)
+	if so.err != nil {
+		return nil, so.err
+	}
+
+	return txData, nil
+}
+
+//Decode 将 16 进制的 blob（如 tx_blob）反序列化成交易 JSON。
+//SWT 金额以 drops 字符串表示，其他货币以 constant.Amount 表示，与底层返回的 tx_json 保持一致。
+func Decode(blob string) (map[string]interface{}, error) {
+	buffer, err := jtUtils.HexToBytes(blob)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid hex string %s", blob)
+	}
+
+	so := new(Serializer)
+	so.Buffer = buffer
+	return so.ToJSON()
+}
+
+//read 从当前位置读取 n 个字节。
+func (so *Serializer) read(n int) []byte {
+	if so.err != nil {
+		return nil
+	}
+
+	if n < 0 || so.pointer+n > len(so.Buffer) {
+		so.err = fmt.Errorf("Buffer out of bounds. Pointer %d, read %d, length %d", so.pointer, n, len(so.Buffer))
+		return nil
+	}
+
+	b := so.Buffer[so.pointer : so.pointer+n]
+	so.pointer += n
+	return b
+}
+
+//readByte 从当前位置读取 1 个字节。
+func (so *Serializer) readByte() byte {
+	b := so.read(1)
+	if b == nil {
+		return 0
+	}
+
+	return b[0]
+}
+
+//peek 查看当前位置的字节，不移动位置。
+func (so *Serializer) peek() (byte, bool) {
+	if so.eof() {
+		return 0, false
+	}
+
+	return so.Buffer[so.pointer], true
+}
+
+//eof 是否已读完。
+func (so *Serializer) eof() bool {
+	return so.pointer >= len(so.Buffer)
+}
@@ -0,0 +1,539 @@
+/**
+ *
+ * 序列化测试类
+ *
+ * @FileName: serializer_test.go
+ */
+
+package serializer
+
+import (
+	"container/list"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"jingtumlib/constant"
+)
+
+func Test_Decode(t *testing.T) {
+	memos := list.New()
+	memos.PushBack(&MemoInfo{Memo: &MemoDataInfo{MemoData: "支付0.1CNY"}})
+	txData := map[string]interface{}{
+		"TransactionType": "Payment",
+		"Flags":           uint32(0),
+		"Fee":             float32(0.01),
+		"Sequence":        uint32(26),
+		"Account":         "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"Destination":     "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+		"Amount":          constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "0.1"},
+		"SendMax":         float64(1.5),
+		"Memos":           memos,
+		"Paths":           [][]PathComputed{{{Account: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS"}}},
+	}
+
+	so, err := FromJSON(txData)
+	if err != nil {
+		t.Fatalf("FromJSON fail : %s", err.Error())
+	}
+
+	decoded, err := Decode(so.ToHex())
+	if err != nil {
+		t.Fatalf("Decode fail : %s", err.Error())
+	}
+
+	if decoded["TransactionType"] != "Payment" {
+		t.Fatalf("TransactionType %v, expect Payment", decoded["TransactionType"])
+	}
+
+	if decoded["Sequence"] != uint32(26) {
+		t.Fatalf("Sequence %v, expect 26", decoded["Sequence"])
+	}
+
+	if decoded["Fee"] != "10000" || decoded["SendMax"] != "1500000" {
+		t.Fatalf("Native amount Fee %v SendMax %v, expect drops", decoded["Fee"], decoded["SendMax"])
+	}
+
+	if amount, _ := decoded["Amount"].(constant.Amount); amount != txData["Amount"] {
+		t.Fatalf("Amount %v, expect %v", decoded["Amount"], txData["Amount"])
+	}
+
+	memo := decoded["Memos"].([]interface{})[0].(map[string]interface{})["Memo"].(map[string]interface{})
+	if memo["MemoData"] != "E694AFE4BB98302E31434E59" {
+		t.Fatalf("MemoData %v", memo["MemoData"])
+	}
+
+	paths := decoded["Paths"].([][]PathComputed)
+	if len(paths) != 1 || paths[0][0].Account != "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS" {
+		t.Fatalf("Paths %v", paths)
+	}
+
+	//截断的 blob 应当反序列化失败
+	hex := so.ToHex()
+	if _, err := Decode(hex[:len(hex)-2]); err == nil {
+		t.Fatalf("Decode truncated blob should fail")
+	}
+}
+
+func Test_LedgerEntryFromJSON(t *testing.T) {
+	entryJSON := `{
+		"Account": "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"Balance": "99999880",
+		"Flags": 0,
+		"LedgerEntryType": "AccountRoot",
+		"OwnerCount": 1,
+		"PreviousTxnID": "2D1F1A6D1C0C8B5F4C2AEE2E8E8B1B4F5A0F7D5C3E1A9B8C7D6E5F4A3B2C1D0E",
+		"PreviousTxnLgrSeq": 1263416,
+		"Sequence": 26,
+		"index": "8C1A4A8C2E6C13C7B62F0A4E3F1D8F3D5A7E5D4C3B2A19081726354453627180"
+	}`
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	so, err := FromJSON(entry)
+	if err != nil {
+		t.Fatalf("FromJSON fail : %s", err.Error())
+	}
+
+	decoded, err := Decode(so.ToHex())
+	if err != nil {
+		t.Fatalf("Decode fail : %s", err.Error())
+	}
+
+	if decoded["LedgerEntryType"] != "AccountRoot" || decoded["Balance"] != "99999880" || decoded["OwnerCount"] != uint32(1) {
+		t.Fatalf("Decoded ledger entry %v", decoded)
+	}
+
+	if _, ok := decoded["index"]; ok {
+		t.Fatalf("index should not be serialized")
+	}
+
+	hash, err := LedgerEntryHash(entry)
+	if err != nil {
+		t.Fatalf("LedgerEntryHash fail : %s", err.Error())
+	}
+
+	entry["LedgerIndex"] = entry["index"]
+	delete(entry, "index")
+	hash2, err := LedgerEntryHash(entry)
+	if err != nil || hash2 != hash || len(hash) != 64 {
+		t.Fatalf("LedgerEntryHash %s, %s", hash, hash2)
+	}
+
+	entry["LedgerEntryType"] = "Unknown"
+	if _, err := FromJSON(entry); err == nil {
+		t.Fatalf("FromJSON with invalid LedgerEntryType should fail")
+	}
+
+	//账本对象类型的编码和名称双向一致
+	for _, code := range []uint8{97, 99, 100, 102, 103, 104, 110, 111, 114, 115} {
+		name, err := getLedgerEntryType(code)
+		if err != nil {
+			t.Fatalf("Ledger entry type %d : %s", code, err.Error())
+		}
+		if value, err := getLedgerEntryType(name); err != nil || value != int(code) {
+			t.Fatalf("Ledger entry type %s encoded as %v, expect %d", name, value, code)
+		}
+	}
+}
+
+func Test_MetaDataFromJSON(t *testing.T) {
+	metaJSON := `{
+		"AffectedNodes": [{
+			"ModifiedNode": {
+				"FinalFields": {
+					"Balance": {"currency": "CNY", "issuer": "jjjjjjjjjjjjjjjjjjjjBZbvri", "value": "-0.1"},
+					"Flags": 1114112,
+					"HighLimit": {"currency": "CNY", "issuer": "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", "value": "0"},
+					"LowLimit": {"currency": "CNY", "issuer": "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "value": "10000"}
+				},
+				"LedgerEntryType": "SkywellState",
+				"LedgerIndex": "8C1A4A8C2E6C13C7B62F0A4E3F1D8F3D5A7E5D4C3B2A19081726354453627180",
+				"PreviousFields": {
+					"Balance": {"currency": "CNY", "issuer": "jjjjjjjjjjjjjjjjjjjjBZbvri", "value": "-0.2"}
+				}
+			}
+		}, {
+			"ModifiedNode": {
+				"FinalFields": {"Account": "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "Balance": "99999880", "Flags": 0, "OwnerCount": 1, "Sequence": 27},
+				"LedgerEntryType": "AccountRoot",
+				"LedgerIndex": "2D1F1A6D1C0C8B5F4C2AEE2E8E8B1B4F5A0F7D5C3E1A9B8C7D6E5F4A3B2C1D0E",
+				"PreviousFields": {"Balance": "99999890", "Sequence": 26}
+			}
+		}],
+		"TransactionIndex": 0,
+		"TransactionResult": "tesSUCCESS",
+		"delivered_amount": {"currency": "CNY", "issuer": "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", "value": "0.1"}
+	}`
+
+	var meta map[string]interface{}
+	if err := json.Unmarshal([]byte(metaJSON), &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	so, err := FromJSON(meta)
+	if err != nil {
+		t.Fatalf("FromJSON fail : %s", err.Error())
+	}
+
+	decoded, err := Decode(so.ToHex())
+	if err != nil {
+		t.Fatalf("Decode fail : %s", err.Error())
+	}
+
+	if decoded["TransactionResult"] != "tesSUCCESS" {
+		t.Fatalf("TransactionResult %v, expect tesSUCCESS", decoded["TransactionResult"])
+	}
+
+	nodes := decoded["AffectedNodes"].([]interface{})
+	node := nodes[0].(map[string]interface{})["ModifiedNode"].(map[string]interface{})
+	balance := node["FinalFields"].(map[string]interface{})["Balance"].(constant.Amount)
+	if balance.Value != "-0.1" || balance.Currency != "CNY" {
+		t.Fatalf("Balance %v, expect -0.1 CNY", balance)
+	}
+
+	node = nodes[1].(map[string]interface{})["ModifiedNode"].(map[string]interface{})
+	if node["PreviousFields"].(map[string]interface{})["Balance"] != "99999890" {
+		t.Fatalf("PreviousFields %v", node["PreviousFields"])
+	}
+}
+
+func Test_FromTx(t *testing.T) {
+	//测试时未加载配置，需指定本币
+	constant.CFGCurrency = "SWT"
+
+	memos := list.New()
+	memos.PushBack(&MemoInfo{Memo: &MemoDataInfo{MemoData: "支付0.1CNY"}})
+	txData := map[string]interface{}{
+		"TransactionType": "Payment",
+		"Flags":           uint32(0),
+		"Fee":             float32(0.01),
+		"Sequence":        uint32(26),
+		"Account":         "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"Destination":     "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+		"Amount":          constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "0.1"},
+		"SendMax":         float64(1.5),
+		"Memos":           memos,
+	}
+
+	so, err := FromJSON(txData)
+	if err != nil {
+		t.Fatalf("FromJSON fail : %s", err.Error())
+	}
+
+	payment := &Payment{
+		TxCommon: TxCommon{
+			Account:  "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+			Sequence: 26,
+			Fee:      10000,
+			Memos:    Memos{{Memo: &MemoDataInfo{MemoData: "支付0.1CNY"}}},
+		},
+		Destination: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+		Amount:      constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "0.1"},
+		SendMax:     &constant.Amount{Currency: "SWT", Value: "1.5"},
+	}
+
+	soTx, err := FromTx(payment)
+	if err != nil {
+		t.Fatalf("FromTx fail : %s", err.Error())
+	}
+
+	if soTx.ToHex() != so.ToHex() {
+		t.Fatalf("FromTx %s, expect %s", soTx.ToHex(), so.ToHex())
+	}
+
+	cancel := &OfferCancel{TxCommon: TxCommon{Account: "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", Fee: 10000, Sequence: 27}, OfferSequence: 26}
+	soTx, err = FromTx(cancel)
+	if err != nil {
+		t.Fatalf("FromTx fail : %s", err.Error())
+	}
+
+	decoded, err := Decode(soTx.ToHex())
+	if err != nil || decoded["TransactionType"] != "OfferCancel" || decoded["OfferSequence"] != uint32(26) || decoded["Fee"] != "10000" {
+		t.Fatalf("Decoded OfferCancel %v, %v", decoded, err)
+	}
+}
+
+//badTagTx 字段名拼写错误的交易
+type badTagTx struct {
+	TxCommon
+	Destination string `jingtum:"Destinaton,omitempty"`
+}
+
+func (tx *badTagTx) TxType() string {
+	return "Payment"
+}
+
+//Test_TxPlan 各交易类型的结构体标签都属于该交易类型的模板；字段都写出，omitempty 的零值字段除外
+func Test_TxPlan(t *testing.T) {
+	common := TxCommon{
+		Flags:              0x80000000,
+		SourceTag:          1,
+		LastLedgerSequence: 100,
+		Account:            "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		Sequence:           26,
+		Fee:                10000,
+		SigningPubKey:      "0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020",
+		TxnSignature:       "3045022100",
+		Memos:              Memos{{Memo: &MemoDataInfo{MemoData: "memo"}}},
+	}
+	amount := constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "0.1"}
+	txs := []TxData{
+		&Payment{TxCommon: common, Destination: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", Amount: amount, SendMax: &amount,
+			Paths: [][]PathComputed{{{Account: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"}}}, InvoiceID: "00", DestinationTag: 2},
+		&OfferCreate{TxCommon: common, TakerPays: amount, TakerGets: amount, Expiration: 3},
+		&OfferCancel{TxCommon: common, OfferSequence: 4},
+		&TrustSet{TxCommon: common, LimitAmount: amount, QualityIn: 5, QualityOut: 6},
+		&RelationSet{TxCommon: common, Target: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", RelationType: 1, LimitAmount: amount},
+		&AccountSet{TxCommon: common, SetFlag: 1, ClearFlag: 2, TransferRate: 3, Domain: "jingtum.com"},
+		&SetRegularKey{TxCommon: common, RegularKey: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"},
+		&ConfigContract{TxCommon: common, Method: 1, Payload: "00", Destination: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+			Amount: &amount, ContractMethod: "foo", Args: Args{"1"}},
+		&Payment{}, &OfferCreate{}, &OfferCancel{}, &TrustSet{}, &RelationSet{}, &AccountSet{}, &SetRegularKey{}, &ConfigContract{},
+	}
+
+	for i, tx := range txs {
+		zero := i >= len(txs)/2
+		fields, err := txPlan(reflect.TypeOf(tx), tx.TxType(), transactionTypes[txTypeStrMapNumber[tx.TxType()]])
+		if err != nil {
+			t.Fatalf("%T txPlan fail : %s", tx, err.Error())
+		}
+
+		txData := make(map[string]interface{})
+		if err := txFields(reflect.ValueOf(tx), fields, txData); err != nil {
+			t.Fatalf("%T txFields fail : %s", tx, err.Error())
+		}
+
+		for _, field := range fields {
+			if _, ok := txData[field.name]; ok != (!zero || !field.omitEmpty) {
+				t.Fatalf("%T field %s written %v, zero %v", tx, field.name, ok, zero)
+			}
+		}
+		if !zero && len(txData) != len(fields) {
+			t.Fatalf("%T txFields %v, expect %d fields", tx, txData, len(fields))
+		}
+	}
+
+	//零值的字段也校验字段名
+	_, err := FromTx(&badTagTx{TxCommon: common})
+	if verr, ok := err.(*ValidationError); !ok || len(verr.NotAllowed) != 1 || verr.NotAllowed[0] != "Destinaton" {
+		t.Fatalf("FromTx with misspelled tag %v, expect ValidationError", err)
+	}
+
+	var payment *Payment
+	if _, err := FromTx(payment); err == nil {
+		t.Fatalf("FromTx of nil Payment expect error")
+	}
+
+	if _, err := FromTx(nil); err == nil {
+		t.Fatalf("FromTx of nil TxData expect error")
+	}
+}
+
+func Test_Validation(t *testing.T) {
+	txData := map[string]interface{}{
+		"TransactionType": "Payment",
+		"Flags":           uint32(0),
+		"Fee":             float32(0.01),
+		"Account":         "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"TakerPays":       float64(1),
+	}
+
+	_, err := FromJSON(txData)
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("FromJSON error %v, expect ValidationError", err)
+	}
+
+	if verr.Type != "Payment" || len(verr.Missing) != 2 || verr.Missing[0] != "Amount" || verr.Missing[1] != "Destination" || len(verr.NotAllowed) != 1 || verr.NotAllowed[0] != "TakerPays" {
+		t.Fatalf("ValidationError %v", verr)
+	}
+
+	//空的 Paths 为默认值，不参与序列化
+	txData = map[string]interface{}{
+		"TransactionType": "Payment",
+		"Fee":             float32(0.01),
+		"Account":         "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"Destination":     "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+		"Amount":          float64(1),
+	}
+	so, err := FromJSON(txData)
+	if err != nil {
+		t.Fatalf("FromJSON fail : %s", err.Error())
+	}
+
+	txData["TransactionType"] = "Payment"
+	txData["Paths"] = [][]PathComputed{}
+	soPaths, err := FromJSON(txData)
+	if err != nil || soPaths.ToHex() != so.ToHex() {
+		t.Fatalf("Empty Paths should be omitted, %v", err)
+	}
+}
+
+//vector 序列化标准用例，tx_json 为底层返回的格式
+type vector struct {
+	Name        string                 `json:"name"`
+	TxJSON      map[string]interface{} `json:"tx_json"`
+	Blob        string                 `json:"blob"`
+	SigningHash string                 `json:"signing_hash"`
+	Hash        string                 `json:"hash"`
+}
+
+//Test_Vectors 离线校验 testdata/vectors.json 中的 JSON -> blob -> 哈希
+func Test_Vectors(t *testing.T) {
+	constant.CFGCurrency = "SWT"
+	data, err := ioutil.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatalf("Read vectors fail : %s", err.Error())
+	}
+
+	var vectors []vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Unmarshal vectors fail : %s", err.Error())
+	}
+
+	for _, v := range vectors {
+		so, err := FromTxJSON(v.TxJSON)
+		if err != nil {
+			t.Fatalf("%s: FromTxJSON fail : %s", v.Name, err.Error())
+		}
+
+		if blob := strings.ToUpper(so.ToHex()); blob != v.Blob {
+			t.Fatalf("%s: blob %s, expect %s", v.Name, blob, v.Blob)
+		}
+
+		unsigned := make(map[string]interface{}, len(v.TxJSON))
+		for k, value := range v.TxJSON {
+			if k != "TxnSignature" {
+				unsigned[k] = value
+			}
+		}
+		soUnsigned, err := FromTxJSON(unsigned)
+		if err != nil {
+			t.Fatalf("%s: FromTxJSON fail : %s", v.Name, err.Error())
+		}
+
+		if hash := strings.ToUpper(hex.EncodeToString(soUnsigned.Hash(constant.HashPrefixTxSign))); hash != v.SigningHash {
+			t.Fatalf("%s: signing hash %s, expect %s", v.Name, hash, v.SigningHash)
+		}
+
+		if hash, err := TransactionID(v.Blob); err != nil || hash != v.Hash {
+			t.Fatalf("%s: hash %s, expect %s, %v", v.Name, hash, v.Hash, err)
+		}
+
+		//反序列化后再序列化，结果不变
+		decoded, err := Decode(v.Blob)
+		if err != nil {
+			t.Fatalf("%s: Decode fail : %s", v.Name, err.Error())
+		}
+
+		soDecoded, err := FromTxJSON(decoded)
+		if err != nil {
+			t.Fatalf("%s: FromTxJSON decoded fail : %s", v.Name, err.Error())
+		}
+
+		if blob := strings.ToUpper(soDecoded.ToHex()); blob != v.Blob {
+			t.Fatalf("%s: decoded blob %s, expect %s", v.Name, blob, v.Blob)
+		}
+	}
+}
+
+func Test_SerializeErrors(t *testing.T) {
+	invalid := map[string]func(map[string]interface{}){
+		"SigningPubKey": func(txData map[string]interface{}) { txData["SigningPubKey"] = "not hex" },
+		"Paths": func(txData map[string]interface{}) {
+			txData["Paths"] = [][]PathComputed{{{Currency: "C", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS"}}}
+		},
+		"Memos": func(txData map[string]interface{}) {
+			memos := list.New()
+			memos.PushBack(&MemoInfo{})
+			txData["Memos"] = memos
+		},
+		"Flags":  func(txData map[string]interface{}) { txData["Flags"] = "0" },
+		"Amount": func(txData map[string]interface{}) { txData["Amount"] = []string{"1"} },
+	}
+
+	for name, modify := range invalid {
+		txData := benchmarkPayment()
+		modify(txData)
+		if _, err := FromJSON(txData); err == nil {
+			t.Fatalf("Invalid %s should fail", name)
+		}
+	}
+
+	//池中复用的 Serializer 不能带有上一次的内容
+	so, err := FromJSON(benchmarkPayment())
+	if err != nil {
+		t.Fatalf("FromJSON fail : %s", err.Error())
+	}
+	expect := so.ToHex()
+	so.Release()
+
+	so, err = FromJSON(benchmarkPayment())
+	if err != nil || so.ToHex() != expect {
+		t.Fatalf("FromJSON after Release %s, %v", so.ToHex(), err)
+	}
+}
+
+func benchmarkPayment() map[string]interface{} {
+	memos := list.New()
+	memos.PushBack(&MemoInfo{Memo: &MemoDataInfo{MemoData: "支付0.1CNY"}})
+	return map[string]interface{}{
+		"TransactionType": "Payment",
+		"Flags":           uint32(0),
+		"Fee":             float32(0.01),
+		"Sequence":        uint32(26),
+		"Account":         "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+		"Destination":     "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+		"Amount":          constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "0.1"},
+		"SendMax":         float64(1.5),
+		"Memos":           memos,
+		"SigningPubKey":   "0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020",
+	}
+}
+
+func BenchmarkFromJSON(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		so, err := FromJSON(benchmarkPayment())
+		if err != nil {
+			b.Fatalf("FromJSON fail : %s", err.Error())
+		}
+		so.Hash(constant.HashPrefixTxSign)
+		so.Release()
+	}
+}
+
+//BenchmarkFromTx 与 BenchmarkFromJSON 为同一笔支付
+func BenchmarkFromTx(b *testing.B) {
+	//测试时未加载配置，需指定本币
+	constant.CFGCurrency = "SWT"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		payment := &Payment{
+			TxCommon: TxCommon{
+				Account:       "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+				Sequence:      26,
+				Fee:           10000,
+				SigningPubKey: "0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020",
+				Memos:         Memos{{Memo: &MemoDataInfo{MemoData: "支付0.1CNY"}}},
+			},
+			Destination: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+			Amount:      constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "0.1"},
+			SendMax:     &constant.Amount{Currency: "SWT", Value: "1.5"},
+		}
+		so, err := FromTx(payment)
+		if err != nil {
+			b.Fatalf("FromTx fail : %s", err.Error())
+		}
+		so.Hash(constant.HashPrefixTxSign)
+		so.Release()
+	}
+}
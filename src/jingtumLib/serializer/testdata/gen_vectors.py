@@ -0,0 +1,505 @@
+#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+"""
+生成 vectors.json 的序列化标准用例，与 Go 代码无关的独立实现。
+
+    python3 gen_vectors.py          重新生成 vectors.json
+    python3 gen_vectors.py --check  只校验 vectors.json 与生成结果一致
+
+来源说明见同目录的 README.md。序列化按 ripple 二进制格式（jingtum 的 base58 字母表）实现，
+字段编码读取 constant/global.go；secp256k1 签名按 RFC6979（HMAC-SHA256）生成 k 并转换成 low-S，
+ed25519 签名按 RFC8032。只依赖 Python 3.8+ 标准库。
+"""
+import hashlib
+import hmac
+import json
+import os
+import re
+import struct
+import sys
+from decimal import Decimal
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+VECTORS = os.path.join(HERE, 'vectors.json')
+GLOBAL_GO = os.path.join(HERE, '..', '..', 'constant', 'global.go')
+
+FIELDS = {m.group(1): (int(m.group(2)), int(m.group(3)))
+          for m in re.finditer(r'"(\w+)":\s*&KeyValuePair\{(\d+), (\d+)\}', open(GLOBAL_GO, encoding='utf-8').read())}
+TXTYPES = {"AccountSet": 3, "TrustSet": 20, "OfferCreate": 7, "OfferCancel": 8, "SetRegularKey": 5, "Payment": 0,
+           "ConfigContract": 30, "RelationSet": 21, "RelationDel": 22}
+ALPHA = "jpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65rkm8oFqi1tuvAxyz"
+
+PREFIX_TX_SIGN = '53545800'
+PREFIX_TX_MULTI_SIGN = '534D5400'
+PREFIX_TRANSACTION_ID = '54584E00'
+FULLY_CANONICAL_SIG = 0x80000000
+
+
+# ---------------------------------------------------------------- base58
+
+def b58decode(s):
+    n = 0
+    for c in s:
+        n = n * 58 + ALPHA.index(c)
+    b = n.to_bytes((n.bit_length() + 7) // 8, 'big')
+    b = b'\0' * (len(s) - len(s.lstrip(ALPHA[0]))) + b
+    payload, chk = b[:-4], b[-4:]
+    assert hashlib.sha256(hashlib.sha256(payload).digest()).digest()[:4] == chk, s
+    return payload
+
+
+def b58encode(version, payload):
+    b = bytes([version]) + payload
+    b += hashlib.sha256(hashlib.sha256(b).digest()).digest()[:4]
+    n = int.from_bytes(b, 'big')
+    out = ''
+    while n:
+        n, r = divmod(n, 58)
+        out = ALPHA[r] + out
+    return ALPHA[0] * (len(b) - len(b.lstrip(b'\0'))) + out
+
+
+def account_id(address):
+    p = b58decode(address)
+    assert p[0] == 0 and len(p) == 21, address
+    return p[1:]
+
+
+def address(pub):
+    return b58encode(0, hashlib.new('ripemd160', hashlib.sha256(pub).digest()).digest())
+
+
+# ---------------------------------------------------------------- 序列化
+
+def header(name):
+    t, f = FIELDS[name]
+    if t < 16 and f < 16:
+        return bytes([t << 4 | f])
+    if t < 16:
+        return bytes([t << 4, f])
+    if f < 16:
+        return bytes([f, t])
+    return bytes([0, t, f])
+
+
+def vl(n):
+    if n <= 192:
+        return bytes([n])
+    if n <= 12480:
+        n -= 193
+        return bytes([193 + (n >> 8), n & 0xff])
+    n -= 12481
+    return bytes([241 + (n >> 16), (n >> 8) & 0xff, n & 0xff])
+
+
+def currency(code):
+    if len(code) == 40:
+        return bytes.fromhex(code)
+    if code == 'SWT':
+        return b'\0' * 20
+    return b'\0' * 12 + code.encode() + b'\0' * 5
+
+
+def amount(v):
+    if isinstance(v, str):
+        d = int(v)
+        return ((0 if d < 0 else 1 << 62) | abs(d)).to_bytes(8, 'big')
+    val = Decimal(v['value'])
+    out = 1 << 63
+    if val != 0:
+        if val > 0:
+            out |= 1 << 62
+        _, digits, exp = abs(val).normalize().as_tuple()
+        m = int(''.join(map(str, digits)))
+        while m < 10 ** 15:
+            m *= 10
+            exp -= 1
+        assert m < 10 ** 16
+        out |= (exp + 97) << 54 | m
+    return out.to_bytes(8, 'big') + currency(v['currency']) + account_id(v['issuer'])
+
+
+def field(name, v):
+    t, _ = FIELDS[name]
+    h = header(name)
+    if t == 16:
+        return h + int(v).to_bytes(1, 'big')
+    if t == 1:
+        return h + int(TXTYPES[v] if name == 'TransactionType' else v).to_bytes(2, 'big')
+    if t == 2:
+        return h + int(v).to_bytes(4, 'big')
+    if t == 3:
+        return h + bytes.fromhex(v.rjust(16, '0'))
+    if t in (4, 5, 17):
+        return h + bytes.fromhex(v)
+    if t == 6:
+        return h + amount(v)
+    if t == 7:
+        b = bytes.fromhex(v)
+        return h + vl(len(b)) + b
+    if t == 8:
+        b = account_id(v)
+        return h + vl(len(b)) + b
+    if t == 14:
+        return h + obj(v) + b'\xe1'
+    if t == 15:
+        out = h
+        for item in v:
+            (k, iv), = item.items()
+            out += field(k, iv)
+        return out + b'\xf1'
+    if t == 18:
+        out = h
+        for i, path in enumerate(v):
+            if i:
+                out += b'\xff'
+            for step in path:
+                typ = (1 if 'account' in step else 0) | (0x10 if 'currency' in step else 0) | (0x20 if 'issuer' in step else 0)
+                out += bytes([typ])
+                if 'account' in step:
+                    out += account_id(step['account'])
+                if 'currency' in step:
+                    out += currency(step['currency'])
+                if 'issuer' in step:
+                    out += account_id(step['issuer'])
+        return out + b'\x00'
+    raise ValueError('type %d of %s' % (t, name))
+
+
+def obj(d):
+    return b''.join(field(k, d[k]) for k in sorted((k for k in d if k[0].isupper()), key=lambda k: FIELDS[k]))
+
+
+def sha512half(data):
+    return hashlib.sha512(data).digest()[:32]
+
+
+def prefixed_hash(prefix, data):
+    return sha512half(bytes.fromhex(prefix) + data).hex().upper()
+
+
+# ---------------------------------------------------------------- secp256k1
+
+P = 2 ** 256 - 2 ** 32 - 977
+N = 0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141
+G = (0x79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798,
+     0x483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8)
+
+
+def ec_add(p, q):
+    if p is None:
+        return q
+    if q is None:
+        return p
+    if p[0] == q[0] and (p[1] + q[1]) % P == 0:
+        return None
+    if p == q:
+        lam = 3 * p[0] * p[0] * pow(2 * p[1], -1, P) % P
+    else:
+        lam = (q[1] - p[1]) * pow(q[0] - p[0], -1, P) % P
+    x = (lam * lam - p[0] - q[0]) % P
+    return x, (lam * (p[0] - x) - p[1]) % P
+
+
+def ec_mul(k, p=G):
+    r = None
+    while k:
+        if k & 1:
+            r = ec_add(r, p)
+        p = ec_add(p, p)
+        k >>= 1
+    return r
+
+
+def compress(p):
+    return bytes([2 + (p[1] & 1)]) + p[0].to_bytes(32, 'big')
+
+
+def decompress(b):
+    x = int.from_bytes(b[1:], 'big')
+    y = pow((x ** 3 + 7) % P, (P + 1) // 4, P)
+    if y & 1 != b[0] & 1:
+        y = P - y
+    return x, y
+
+
+def secp_scalar(data, discrim=None):
+    i = 0
+    while True:
+        k = int.from_bytes(sha512half(data + (b'' if discrim is None else struct.pack('>I', discrim)) + struct.pack('>I', i)), 'big')
+        if 0 < k < N:
+            return k
+        i += 1
+
+
+def secp_key(secret):
+    """ripple 账号 0 的私钥：根私钥 + 由根公钥派生的附加值"""
+    seed = b58decode(secret)[1:]
+    root = secp_scalar(seed)
+    key = (root + secp_scalar(compress(ec_mul(root)), 0)) % N
+    return key, compress(ec_mul(key))
+
+
+def rfc6979_k(key, h):
+    x = key.to_bytes(32, 'big')
+    h = (int.from_bytes(h, 'big') % N).to_bytes(32, 'big')
+    v, k = b'\x01' * 32, b'\x00' * 32
+    k = hmac.new(k, v + b'\x00' + x + h, hashlib.sha256).digest()
+    v = hmac.new(k, v, hashlib.sha256).digest()
+    k = hmac.new(k, v + b'\x01' + x + h, hashlib.sha256).digest()
+    v = hmac.new(k, v, hashlib.sha256).digest()
+    while True:
+        v = hmac.new(k, v, hashlib.sha256).digest()
+        nonce = int.from_bytes(v, 'big')
+        if 0 < nonce < N:
+            return nonce
+        k = hmac.new(k, v + b'\x00', hashlib.sha256).digest()
+        v = hmac.new(k, v, hashlib.sha256).digest()
+
+
+def der_int(n):
+    b = n.to_bytes((n.bit_length() + 7) // 8, 'big')
+    if b[0] & 0x80:
+        b = b'\0' + b
+    return b'\x02' + bytes([len(b)]) + b
+
+
+def der_decode(sig):
+    assert sig[0] == 0x30 and sig[1] == len(sig) - 2 and sig[2] == 2
+    lr = sig[3]
+    o = 4 + lr
+    assert sig[o] == 2
+    return int.from_bytes(sig[4:o], 'big'), int.from_bytes(sig[o + 2:o + 2 + sig[o + 1]], 'big')
+
+
+def secp_sign(key, message):
+    h = sha512half(message)
+    z = int.from_bytes(h, 'big')
+    k = rfc6979_k(key, h)
+    r = ec_mul(k)[0] % N
+    s = pow(k, -1, N) * (z + r * key) % N
+    if s > N // 2:
+        s = N - s
+    body = der_int(r) + der_int(s)
+    return b'\x30' + bytes([len(body)]) + body
+
+
+def secp_verify(pub, message, sig):
+    r, s = der_decode(sig)
+    z = int.from_bytes(sha512half(message), 'big')
+    w = pow(s, -1, N)
+    pt = ec_add(ec_mul(z * w % N), ec_mul(r * w % N, decompress(pub)))
+    return pt is not None and pt[0] % N == r
+
+
+# ---------------------------------------------------------------- ed25519 (RFC8032)
+
+EQ = 2 ** 255 - 19
+EL = 2 ** 252 + 27742317777372353535851937790883648493
+ED = -121665 * pow(121666, -1, EQ) % EQ
+EI = pow(2, (EQ - 1) // 4, EQ)
+
+
+def ed_x(y, sign):
+    xx = (y * y - 1) * pow(ED * y * y + 1, -1, EQ) % EQ
+    x = pow(xx, (EQ + 3) // 8, EQ)
+    if (x * x - xx) % EQ:
+        x = x * EI % EQ
+    if x & 1 != sign:
+        x = EQ - x
+    return x
+
+
+EB = (ed_x(4 * pow(5, -1, EQ) % EQ, 0), 4 * pow(5, -1, EQ) % EQ, 1, ed_x(4 * pow(5, -1, EQ) % EQ, 0) * 4 * pow(5, -1, EQ) % EQ)
+
+
+def ed_add(p, q):
+    a = (p[1] - p[0]) * (q[1] - q[0]) % EQ
+    b = (p[1] + p[0]) * (q[1] + q[0]) % EQ
+    c = 2 * p[3] * q[3] * ED % EQ
+    d = 2 * p[2] * q[2] % EQ
+    e, f, g, h = b - a, d - c, d + c, b + a
+    return e * f % EQ, g * h % EQ, f * g % EQ, e * h % EQ
+
+
+def ed_mul(k, p=EB):
+    r = (0, 1, 1, 0)
+    while k:
+        if k & 1:
+            r = ed_add(r, p)
+        p = ed_add(p, p)
+        k >>= 1
+    return r
+
+
+def ed_encode(p):
+    zi = pow(p[2], -1, EQ)
+    x, y = p[0] * zi % EQ, p[1] * zi % EQ
+    return (y | (x & 1) << 255).to_bytes(32, 'little')
+
+
+def ed_key(secret):
+    """ed25519 私钥种子为 16 字节熵的 sha512 half，公钥加 0xED 前缀"""
+    raw = b58decode(secret)
+    assert raw[:3] == b'\x01\xe1\x4b' and len(raw) == 19, secret
+    h = hashlib.sha512(sha512half(raw[3:])).digest()
+    a = int.from_bytes(h[:32], 'little') & ((1 << 254) - 8) | (1 << 254)
+    return (a, h[32:]), b'\xed' + ed_encode(ed_mul(a))
+
+
+def ed_sign(key, message):
+    a, prefix = key
+    pub = ed_encode(ed_mul(a))
+    r = int.from_bytes(hashlib.sha512(prefix + message).digest(), 'little') % EL
+    rs = ed_encode(ed_mul(r))
+    k = int.from_bytes(hashlib.sha512(rs + pub + message).digest(), 'little') % EL
+    return rs + ((r + k * a) % EL).to_bytes(32, 'little')
+
+
+# ---------------------------------------------------------------- 用例
+
+ACCOUNT_SECRET = "ssc5eiFivvU2otV6bSYmJeZrAsQK3"
+SIGNER2_SECRET = "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"
+A = "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72"
+D = "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"
+I = "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS"
+
+
+def hx(s):
+    return s.encode().hex().upper()
+
+
+def iou(cur, v):
+    return {"currency": cur, "issuer": I, "value": v}
+
+
+def tx(tx_type, seq, fields, flags=0, fee="10000", account=A):
+    out = {"TransactionType": tx_type, "Flags": flags, "Account": account, "Sequence": seq, "Fee": fee, "SigningPubKey": ""}
+    out.update(fields)
+    return out
+
+
+#(名称, tx_json, 是否签名)。签名用例由 ACCOUNT_SECRET 签名，与 transaction_test.go 中 Test_SigningVectors 的构造一致
+SPECS = [
+    ("payment_swt", tx("Payment", 26, {"Destination": D, "Amount": "100", "Memos": [{"Memo": {"MemoData": hx("支付0.0001SWT")}}]}), True),
+    ("payment_iou", tx("Payment", 27, {"Destination": D, "Amount": iou("CNY", "0.1"), "SendMax": iou("CNY", "0.1001"), "DestinationTag": 12345,
+                                       "InvoiceID": "6A8C1F4E0D8A2F5D1E54B8C7E3A1F0B9C2D7E6F5A4B3C2D1E0F9A8B7C6D5E4F3"}), True),
+    ("payment_paths", tx("Payment", 28, {"Destination": D, "Amount": iou("USD", "1234.5678"), "SendMax": "2000000",
+                                         "Paths": [[{"currency": "CNY", "issuer": I}, {"account": I}], [{"currency": "USD", "issuer": I}]]}, flags=131072), False),
+    ("payment_memos", tx("Payment", 29, {"Destination": D, "Amount": "1", "Memos": [
+        {"Memo": {"MemoType": hx("text"), "MemoData": hx("hello"), "MemoFormat": hx("text/plain")}},
+        {"Memo": {"MemoData": hx('{"a":1}'), "MemoFormat": hx("json")}}]}), False),
+    ("payment_hex_currency", tx("Payment", 30, {"Destination": D, "Amount": iou("8100000036000020160622201606300120000002", "1e-5"),
+                                                "LastLedgerSequence": 8888888, "SourceTag": 7}), False),
+    ("offer_create_sell", tx("OfferCreate", 31, {"TakerPays": iou("CNY", "12.5"), "TakerGets": "1000000000", "Expiration": 600000000}, flags=524288), True),
+    ("offer_create_iou", tx("OfferCreate", 32, {"TakerPays": iou("USD", "9999999999999999e80"), "TakerGets": iou("CNY", "-0.0000000000000001")}), False),
+    ("offer_cancel", tx("OfferCancel", 33, {"OfferSequence": 31}), True),
+    ("trust_set", tx("TrustSet", 34, {"LimitAmount": iou("CNY", "10000"), "QualityIn": 1000000000, "QualityOut": 950000000}), False),
+    ("trust_set_zero", tx("TrustSet", 35, {"LimitAmount": iou("CNY", "0")}, flags=FULLY_CANONICAL_SIG), False),
+    ("relation_set_authorize", tx("RelationSet", 36, {"Target": D, "RelationType": 1, "LimitAmount": iou("CNY", "100")}), True),
+    ("relation_del_freeze", tx("RelationDel", 37, {"Target": D, "RelationType": 3, "LimitAmount": iou("CNY", "0.5")}), False),
+    ("account_set", tx("AccountSet", 38, {"SetFlag": 8, "TransferRate": 1002000000, "Domain": hx("example.com")}), False),
+    ("set_regular_key", tx("SetRegularKey", 39, {"RegularKey": D}), False),
+    ("contract_deploy", tx("ConfigContract", 40, {"Method": 0, "Amount": "10000000", "Payload": hx("result={}; function Init(t) return result end"),
+                                                  "Args": [{"Arg": {"Parameter": hx("10")}}, {"Arg": {"Parameter": hx("abc")}}]}, fee="10000000"), True),
+    ("contract_call", tx("ConfigContract", 41, {"Method": 1, "Destination": D, "ContractMethod": hx("foo"), "Args": [{"Arg": {"Parameter": hx("1")}}]}), False),
+]
+
+#多重签名：D 账号的支付由两个签名者（secp256k1 和 ed25519）签名，签名者按 AccountID 升序排列
+MULTISIGNED = ("payment_multisigned", tx("Payment", 8, {"Destination": I, "Amount": "100000000"}, fee="30000", account=D))
+
+
+def vector(name, tx_json):
+    blob = obj(tx_json)
+    unsigned = {k: v for k, v in tx_json.items() if k != 'TxnSignature'}
+    return {"name": name, "tx_json": tx_json, "blob": blob.hex().upper(),
+            "signing_hash": prefixed_hash(PREFIX_TX_SIGN, obj(unsigned)),
+            "hash": prefixed_hash(PREFIX_TRANSACTION_ID, blob)}
+
+
+def single_signed(tx_json, key, pub):
+    tx_json = dict(tx_json, SigningPubKey=pub.hex().upper())
+    tx_json["TxnSignature"] = secp_sign(key, bytes.fromhex(PREFIX_TX_SIGN) + obj(tx_json)).hex().upper()
+    return tx_json
+
+
+def multi_signed(tx_json, signers):
+    items = []
+    for sign, pub in signers:
+        account = address(pub)
+        sig = sign(bytes.fromhex(PREFIX_TX_MULTI_SIGN) + obj(tx_json) + account_id(account))
+        items.append((account_id(account), {"Signer": {"Account": account, "SigningPubKey": pub.hex().upper(), "TxnSignature": sig.hex().upper()}}))
+    return dict(tx_json, TxnSignatures=[item for _, item in sorted(items, key=lambda item: item[0])])
+
+
+def generate():
+    key, pub = secp_key(ACCOUNT_SECRET)
+    assert address(pub) == A
+    ed, ed_pub = ed_key(SIGNER2_SECRET)
+
+    #签名用例各有两个：本地签名设置 FullyCanonicalSig 标志的用例，
+    #以及设置该标志之前的 Flags 原值用例（名称以 _flags0 结尾），用于反序列化和验签
+    vectors = []
+    for name, tx_json, signed in SPECS:
+        tx_json = dict(tx_json, SigningPubKey=pub.hex().upper())
+        if not signed:
+            vectors.append(vector(name, tx_json))
+            continue
+        vectors.append(vector(name, single_signed(dict(tx_json, Flags=tx_json["Flags"] | FULLY_CANONICAL_SIG), key, pub)))
+        vectors.append(vector(name + '_flags0', single_signed(tx_json, key, pub)))
+
+    name, tx_json = MULTISIGNED
+    signers = [(lambda m: secp_sign(key, m), pub), (lambda m: ed_sign(ed, m), ed_pub)]
+    vectors.append(vector(name, multi_signed(dict(tx_json, Flags=tx_json["Flags"] | FULLY_CANONICAL_SIG), signers)))
+    vectors.append(vector(name + '_flags0', multi_signed(tx_json, signers)))
+    return vectors
+
+
+def verify_signatures(v):
+    """用独立的验签实现校验用例中的签名"""
+    tx_json = v["tx_json"]
+    unsigned = {k: x for k, x in tx_json.items() if k not in ('TxnSignature', 'TxnSignatures')}
+    if 'TxnSignature' in tx_json:
+        message = bytes.fromhex(PREFIX_TX_SIGN) + obj(unsigned)
+        assert secp_verify(bytes.fromhex(tx_json['SigningPubKey']), message, bytes.fromhex(tx_json['TxnSignature'])), v["name"]
+    for item in tx_json.get('TxnSignatures', []):
+        signer = item['Signer']
+        pub, sig = bytes.fromhex(signer['SigningPubKey']), bytes.fromhex(signer['TxnSignature'])
+        message = bytes.fromhex(PREFIX_TX_MULTI_SIGN) + obj(unsigned) + account_id(signer['Account'])
+        if pub[0] == 0xED:
+            assert ed_sign_matches(pub, message, sig), v["name"]
+        else:
+            assert secp_verify(pub, message, sig), v["name"]
+
+
+def ed_sign_matches(pub, message, sig):
+    ya = int.from_bytes(pub[1:], 'little')
+    pa = ed_x(ya & ((1 << 255) - 1), ya >> 255), ya & ((1 << 255) - 1)
+    pa = (pa[0], pa[1], 1, pa[0] * pa[1] % EQ)
+    yr = int.from_bytes(sig[:32], 'little')
+    pr = ed_x(yr & ((1 << 255) - 1), yr >> 255), yr & ((1 << 255) - 1)
+    pr = (pr[0], pr[1], 1, pr[0] * pr[1] % EQ)
+    k = int.from_bytes(hashlib.sha512(sig[:32] + pub[1:] + message).digest(), 'little') % EL
+    return ed_encode(ed_mul(int.from_bytes(sig[32:], 'little'))) == ed_encode(ed_add(pr, ed_mul(k, pa)))
+
+
+def main():
+    vectors = generate()
+    for v in vectors:
+        verify_signatures(v)
+
+    if '--check' in sys.argv[1:]:
+        existing = json.load(open(VECTORS, encoding='utf-8'))
+        if existing != vectors:
+            bad = [v["name"] for v, e in zip(vectors, existing) if v != e] or ['length']
+            sys.exit('vectors.json differs from generated vectors: %s' % ', '.join(bad))
+        print('vectors.json OK (%d vectors)' % len(vectors))
+        return
+
+    with open(VECTORS, 'w', encoding='utf-8') as f:
+        f.write(json.dumps(vectors, ensure_ascii=False, indent=2) + '\n')
+    print('wrote %d vectors' % len(vectors))
+
+
+if __name__ == '__main__':
+    main()
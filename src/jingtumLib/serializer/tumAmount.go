@@ -14,15 +14,13 @@ package serializer
 
 import (
 	"fmt"
-	"math"
 	"math/big"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"jingtumlib/constant"
 	"jingtumlib/utils"
-
-	"github.com/yangxuebo-138/decimal"
 )
 
 //TumAmount 金额结构体。
@@ -37,10 +35,26 @@ type TumAmount struct {
 	Offset     int
 }
 
+const (
+	//iouPrecision 非本币金额的有效数字位数
+	iouPrecision = 16
+	//minIOUOffset 非本币金额的最小指数
+	minIOUOffset = -96
+	//maxIOUOffset 非本币金额的最大指数
+	maxIOUOffset = 80
+)
+
 var (
 	biXnsMax = big.NewInt(9000000000000000000)
 	biXnsMin = big.NewInt(-9000000000000000000)
 
+	//iouValueRegexp 非本币金额：符号、整数部分、小数部分、指数
+	iouValueRegexp = regexp.MustCompile(`^([-+]?)(\d*)(?:\.(\d*))?(?:[eE]([-+]?\d+))?$`)
+	//swtValueRegexp 本币金额：符号、整数部分、至多6位小数
+	swtValueRegexp = regexp.MustCompile(`^(-?)(\d*)(?:\.(\d{0,6}))?$`)
+	//dropsRegexp 以 drops 表示的本币金额
+	dropsRegexp = regexp.MustCompile(`^-?\d+$`)
+
 	//ConfigCurrencty 配置的货币
 	ConfigCurrencty string
 )
@@ -75,7 +89,36 @@ func fromJSON(inJSON interface{}) (*TumAmount, error) {
 	return tumAmount, nil
 }
 
+//fromDrops 解析底层返回的 drops 金额字符串，如账本对象中的 Balance
+func fromDrops(drops string) (*TumAmount, error) {
+	if !dropsRegexp.MatchString(drops) {
+		return nil, fmt.Errorf("Invalid drops amount %s", drops)
+	}
+
+	value, ok := big.NewInt(0).SetString(drops, 10)
+	if !ok {
+		return nil, fmt.Errorf("Invalid drops amount %s", drops)
+	}
+
+	tumAmount := NewTumAmount()
+	tumAmount.IsNegative = value.Sign() < 0
+	tumAmount.Value = value.Abs(value)
+	if tumAmount.Value.Cmp(biXnsMax) > 0 {
+		return nil, fmt.Errorf("Drops amount out of bounds %s", drops)
+	}
+
+	return tumAmount, nil
+}
+
 func (amount *TumAmount) parseJSON(inJSON interface{}) error {
+	if jsonMap, ok := inJSON.(map[string]interface{}); ok {
+		jsonAmount := constant.Amount{}
+		jsonAmount.Currency, _ = jsonMap["currency"].(string)
+		jsonAmount.Issuer, _ = jsonMap["issuer"].(string)
+		jsonAmount.Value, _ = jsonMap["value"].(string)
+		inJSON = jsonAmount
+	}
+
 	if utils.IsNumberType(inJSON) {
 		err := amount.parseSwtValue(utils.NumberToString(inJSON))
 		if err != nil {
@@ -87,7 +130,7 @@ func (amount *TumAmount) parseJSON(inJSON interface{}) error {
 			return err
 		}
 	} else if jsonAmount, ok := inJSON.(constant.Amount); ok {
-		if jsonAmount.Currency == constant.CFGCurrency {
+		if jsonAmount.Currency == nativeCurrency() {
 			err := amount.parseSwtValue(jsonAmount.Value)
 			if err != nil {
 				return err
@@ -99,26 +142,13 @@ func (amount *TumAmount) parseJSON(inJSON interface{}) error {
 				return fmt.Errorf("Input Amount has invalid issuer info %s", jsonAmount.Issuer)
 			}
 			amount.Issuer = jsonAmount.Issuer
-			value, err := strconv.ParseFloat(jsonAmount.Value, 64)
+			mantissa, offset, isNegative, err := normalizeIOU(jsonAmount.Value)
 			if err != nil {
-				return fmt.Errorf("Input JSON swt value invalid %s", jsonAmount.Value)
-			}
-
-			valueStr := fmt.Sprintf("%.16e", value)
-			powStr := valueStr[strings.LastIndex(valueStr, "e")+1:]
-			vpow, pintErr := strconv.ParseInt(powStr, 10, 64)
-			if pintErr != nil {
-				return fmt.Errorf("pow parse int value invalid %s", powStr)
-			}
-			offset := 15 - vpow
-			value = value * math.Pow10(int(15-vpow))
-
-			bIntV, ok := big.NewInt(0).SetString(fmt.Sprintf("%0.0f", value), 10)
-			if !ok {
-				return fmt.Errorf("Input JSON swt value invalid %v", value)
+				return err
 			}
-			amount.Value = bIntV
-			amount.Offset = int(-offset)
+			amount.Value = mantissa
+			amount.Offset = offset
+			amount.IsNegative = isNegative
 			return nil
 		}
 	}
@@ -126,6 +156,49 @@ func (amount *TumAmount) parseJSON(inJSON interface{}) error {
 	return nil
 }
 
+//normalizeIOU 将非本币金额的十进制字符串（可带指数，如 1.5e-3）精确转换为 mantissa * 10^offset，
+//mantissa 为 16 位有效数字（10^15 <= mantissa < 10^16），offset 范围为 [minIOUOffset, maxIOUOffset]。
+//零值返回的 mantissa 为 0。有效数字超过 16 位或 offset 越界时返回错误，不做舍入。
+func normalizeIOU(value string) (*big.Int, int, bool, error) {
+	matches := iouValueRegexp.FindStringSubmatch(value)
+	if matches == nil || matches[2]+matches[3] == "" {
+		return nil, 0, false, fmt.Errorf("Invalid amount value %s", value)
+	}
+
+	offset := 0
+	if matches[4] != "" {
+		exp, err := strconv.Atoi(matches[4])
+		if err != nil || exp > 1000 || exp < -1000 {
+			return nil, 0, false, fmt.Errorf("Amount value exponent out of range %s", value)
+		}
+		offset = exp
+	}
+
+	digits := strings.TrimLeft(matches[2]+matches[3], "0")
+	offset -= len(matches[3])
+	if digits == "" {
+		return big.NewInt(0), 0, false, nil
+	}
+
+	trimmed := strings.TrimRight(digits, "0")
+	offset += len(digits) - len(trimmed)
+	digits = trimmed
+
+	if len(digits) > iouPrecision {
+		return nil, 0, false, fmt.Errorf("Amount value precision out of range %s, at most %d significant digits", value, iouPrecision)
+	}
+
+	offset -= iouPrecision - len(digits)
+	digits += strings.Repeat("0", iouPrecision-len(digits))
+
+	if offset < minIOUOffset || offset > maxIOUOffset {
+		return nil, 0, false, fmt.Errorf("Amount value out of range %s", value)
+	}
+
+	mantissa, _ := big.NewInt(0).SetString(digits, 10)
+	return mantissa, offset, matches[1] == "-", nil
+}
+
 //TumToBytes 金额转字节
 func (amount *TumAmount) TumToBytes() ([]byte, error) {
 	var currencyData = make([]byte, 20)
@@ -154,35 +227,25 @@ func (amount *TumAmount) TumToBytes() ([]byte, error) {
 }
 
 func (amount *TumAmount) parseSwtValue(jsonStr string) error {
-	if !utils.MatchString("^(-?)(\\d*)(\\.\\d{0,6})?$", jsonStr) {
+	matches := swtValueRegexp.FindStringSubmatch(jsonStr)
+	if matches == nil {
 		amount.Value = nil
 		return nil
 	}
 
-	value, err := decimal.NewFromString(jsonStr)
-
-	if err != nil {
+	if matches[2]+matches[3] == "" {
 		return fmt.Errorf("Input JSON swt value invalid %s", jsonStr)
 	}
 
-	amount.IsNative = true
-	amount.Offset = 0
-	vflt64, ok := value.Float64()
-	if ok {
-		amount.IsNegative = vflt64 < 0
-	}
-
-	if amount.IsNegative {
-		value = decimal.NewFromFloat(-vflt64)
-	}
-
-	value = value.Mul(decimal.NewFromFloat(float64(1000000)))
-	//bigIntStr := fmt.Sprintf("%0.0f", value)
-	bIntV, ok := big.NewInt(0).SetString(value.String(), 10)
+	//整数部分与补足6位的小数部分拼接即为 drops，不经浮点运算
+	bIntV, ok := big.NewInt(0).SetString(matches[2]+matches[3]+strings.Repeat("0", 6-len(matches[3])), 10)
 	if !ok {
-		return fmt.Errorf("Input JSON swt value invalid %v", value)
+		return fmt.Errorf("Input JSON swt value invalid %s", jsonStr)
 	}
 
+	amount.IsNative = true
+	amount.Offset = 0
+	amount.IsNegative = matches[1] == "-" && bIntV.Sign() != 0
 	amount.Value = bIntV
 
 	if amount.Value.Cmp(biXnsMax) > 0 {
@@ -196,3 +259,267 @@ func (amount *TumAmount) parseSwtValue(jsonStr string) error {
 func (amount *TumAmount) IsValid() bool {
 	return amount.Value != nil
 }
+
+//decimalString 将 mantissa * 10^offset 转成十进制字符串，去掉小数末尾的零
+func decimalString(mantissa *big.Int, offset int, isNegative bool) string {
+	if mantissa.Sign() == 0 {
+		return "0"
+	}
+
+	digits := mantissa.String()
+	var value string
+	if offset >= 0 {
+		value = digits + strings.Repeat("0", offset)
+	} else {
+		point := len(digits) + offset
+		if point <= 0 {
+			digits = strings.Repeat("0", 1-point) + digits
+			point = 1
+		}
+		value = strings.TrimRight(digits[:point]+"."+digits[point:], "0")
+		value = strings.TrimSuffix(value, ".")
+	}
+
+	if isNegative {
+		return "-" + value
+	}
+
+	return value
+}
+
+//nativeCurrency 本币名称，未加载配置时为 SWT
+func nativeCurrency() string {
+	if constant.CFGCurrency != "" {
+		return constant.CFGCurrency
+	}
+	return "SWT"
+}
+
+//ParseAmount 解析金额，支持 constant.Amount、{currency, issuer, value} 形式的 map，
+//以及数字或字符串形式的本币金额（以 SWT 为单位）。
+func ParseAmount(amount interface{}) (*TumAmount, error) {
+	tumAmount, err := fromJSON(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tumAmount.IsValid() {
+		return nil, fmt.Errorf("Invalid amount %v", amount)
+	}
+
+	return tumAmount, nil
+}
+
+//AmountFromDrops 解析以 drops 表示的本币金额，如底层返回的 Balance
+func AmountFromDrops(drops string) (*TumAmount, error) {
+	return fromDrops(drops)
+}
+
+//ToAmount 转换成 constant.Amount，本币金额以 SWT 为单位
+func (amount *TumAmount) ToAmount() constant.Amount {
+	if amount.IsNative {
+		return constant.Amount{Currency: nativeCurrency(), Value: decimalString(amount.value(), -6, amount.IsNegative)}
+	}
+
+	return constant.Amount{Currency: amount.Currency, Issuer: amount.Issuer, Value: decimalString(amount.value(), amount.Offset, amount.IsNegative)}
+}
+
+//Drops 本币金额的 drops 字符串
+func (amount *TumAmount) Drops() (string, error) {
+	if !amount.IsNative {
+		return "", fmt.Errorf("Amount %s is not native", amount.Currency)
+	}
+
+	return decimalString(amount.value(), 0, amount.IsNegative), nil
+}
+
+//Negate 取反
+func (amount *TumAmount) Negate() *TumAmount {
+	result := *amount
+	result.Value = new(big.Int).Set(amount.value())
+	result.IsNegative = !amount.IsNegative && result.Value.Sign() != 0
+	return &result
+}
+
+//Compare 比较金额，a < b 返回 -1，相等返回 0，a > b 返回 1。不同货币不能比较
+func (amount *TumAmount) Compare(other *TumAmount) (int, error) {
+	if err := amount.checkSameCurrency(other); err != nil {
+		return 0, err
+	}
+
+	return amount.rat().Cmp(other.rat()), nil
+}
+
+//Add 加法，不同货币不能相加
+func (amount *TumAmount) Add(other *TumAmount) (*TumAmount, error) {
+	if err := amount.checkSameCurrency(other); err != nil {
+		return nil, err
+	}
+
+	return amount.withRat(new(big.Rat).Add(amount.rat(), other.rat()))
+}
+
+//Subtract 减法，不同货币不能相减
+func (amount *TumAmount) Subtract(other *TumAmount) (*TumAmount, error) {
+	if err := amount.checkSameCurrency(other); err != nil {
+		return nil, err
+	}
+
+	return amount.withRat(new(big.Rat).Sub(amount.rat(), other.rat()))
+}
+
+//Multiply 乘以数值 factor，如数量乘以价格，结果的货币与 amount 相同。
+//结果本币舍入到 drops，非本币舍入到 16 位有效数字
+func (amount *TumAmount) Multiply(factor *big.Rat) (*TumAmount, error) {
+	if factor == nil {
+		return nil, fmt.Errorf("Invalid factor")
+	}
+
+	return amount.withRat(new(big.Rat).Mul(amount.rat(), factor))
+}
+
+//Divide 除以数值 divisor，结果的货币与 amount 相同。舍入同 Multiply
+func (amount *TumAmount) Divide(divisor *big.Rat) (*TumAmount, error) {
+	if divisor == nil {
+		return nil, fmt.Errorf("Invalid divisor")
+	}
+
+	if divisor.Sign() == 0 {
+		return nil, fmt.Errorf("Division by zero")
+	}
+
+	return amount.withRat(new(big.Rat).Quo(amount.rat(), divisor))
+}
+
+//Ratio 两个金额数值的精确比值 amount / other，本币以 drops 计，同 Quality
+func (amount *TumAmount) Ratio(other *TumAmount) (*big.Rat, error) {
+	if other.IsZeroM() {
+		return nil, fmt.Errorf("Division by zero")
+	}
+
+	return new(big.Rat).Quo(amount.dropsRat(), other.dropsRat()), nil
+}
+
+//Quality 挂单质量 takerPays / takerGets，即 Ratio 保留 16 位有效数字，与底层 book_offers 返回的 quality 一致
+func Quality(takerPays *TumAmount, takerGets *TumAmount) (string, error) {
+	ratio, err := takerPays.Ratio(takerGets)
+	if err != nil {
+		return "", err
+	}
+
+	quality, err := (&TumAmount{}).withRat(ratio)
+	if err != nil {
+		return "", err
+	}
+
+	return decimalString(quality.Value, quality.Offset, quality.IsNegative), nil
+}
+
+//checkSameCurrency 货币（非本币含发行方）相同
+func (amount *TumAmount) checkSameCurrency(other *TumAmount) error {
+	if amount.IsNative && other.IsNative {
+		return nil
+	}
+
+	if amount.IsNative != other.IsNative || amount.Currency != other.Currency || amount.Issuer != other.Issuer {
+		return fmt.Errorf("Cannot operate on different currencies %s and %s", amount.ToAmount().Currency, other.ToAmount().Currency)
+	}
+
+	return nil
+}
+
+func (amount *TumAmount) value() *big.Int {
+	if amount.Value == nil {
+		return big.NewInt(0)
+	}
+	return amount.Value
+}
+
+//rat 金额数值，本币以 SWT 为单位
+func (amount *TumAmount) rat() *big.Rat {
+	r := new(big.Rat).SetInt(amount.value())
+	if amount.IsNative {
+		r.Mul(r, pow10Rat(-6))
+	} else {
+		r.Mul(r, pow10Rat(amount.Offset))
+	}
+
+	if amount.IsNegative {
+		r.Neg(r)
+	}
+	return r
+}
+
+//dropsRat 金额数值，本币以 drops 为单位
+func (amount *TumAmount) dropsRat() *big.Rat {
+	if !amount.IsNative {
+		return amount.rat()
+	}
+	return new(big.Rat).Mul(amount.rat(), pow10Rat(6))
+}
+
+//withRat 以 amount 的货币构造数值为 r 的金额
+func (amount *TumAmount) withRat(r *big.Rat) (*TumAmount, error) {
+	result := &TumAmount{Currency: amount.Currency, Issuer: amount.Issuer, IsNative: amount.IsNative}
+	abs := new(big.Rat).Abs(r)
+
+	if amount.IsNative {
+		result.Value = roundRat(abs.Mul(abs, pow10Rat(6)))
+		if result.Value.Cmp(biXnsMax) > 0 {
+			return nil, fmt.Errorf("Amount value out of range")
+		}
+		result.IsNegative = r.Sign() < 0 && result.Value.Sign() != 0
+		return result, nil
+	}
+
+	if abs.Sign() == 0 {
+		result.Value = big.NewInt(0)
+		return result, nil
+	}
+
+	minMantissa := new(big.Int).Exp(big.NewInt(10), big.NewInt(iouPrecision-1), nil)
+	maxMantissa := new(big.Int).Exp(big.NewInt(10), big.NewInt(iouPrecision), nil)
+	offset := len(abs.Num().String()) - len(abs.Denom().String()) - iouPrecision
+	for {
+		mantissa := roundRat(new(big.Rat).Mul(abs, pow10Rat(-offset)))
+		if mantissa.Cmp(maxMantissa) >= 0 {
+			offset++
+			continue
+		}
+		if mantissa.Cmp(minMantissa) < 0 {
+			offset--
+			continue
+		}
+		result.Value = mantissa
+		break
+	}
+
+	//小于最小值时为零，超出最大值时报错
+	if offset < minIOUOffset {
+		result.Value = big.NewInt(0)
+		return result, nil
+	}
+	if offset > maxIOUOffset {
+		return nil, fmt.Errorf("Amount value out of range")
+	}
+
+	result.Offset = offset
+	result.IsNegative = r.Sign() < 0
+	return result, nil
+}
+
+//pow10Rat 10 的 n 次方
+func pow10Rat(n int) *big.Rat {
+	if n >= 0 {
+		return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+	}
+	return new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n)), nil))
+}
+
+//roundRat 非负数四舍五入取整
+func roundRat(r *big.Rat) *big.Int {
+	num := new(big.Int).Mul(r.Num(), big.NewInt(2))
+	num.Add(num, r.Denom())
+	den := new(big.Int).Mul(r.Denom(), big.NewInt(2))
+	return num.Quo(num, den)
+}
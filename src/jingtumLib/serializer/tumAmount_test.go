@@ -0,0 +1,173 @@
+/**
+ *
+ * 金额测试类
+ *
+ * @FileName: tumAmount_test.go
+ */
+
+package serializer
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"jingtumlib/constant"
+)
+
+func Test_NormalizeIOU(t *testing.T) {
+	cases := []struct {
+		value    string
+		mantissa string
+		offset   int
+		negative bool
+	}{
+		{"0.1", "1000000000000000", -16, false},
+		{"-0.1", "1000000000000000", -16, true},
+		{"1", "1000000000000000", -15, false},
+		{"100", "1000000000000000", -13, false},
+		{"1234567890123456", "1234567890123456", 0, false},
+		{"0.00000000000000001", "1000000000000000", -32, false},
+		{"123.4560", "1234560000000000", -13, false},
+		{"1.5e-3", "1500000000000000", -18, false},
+		{"9999999999999999e80", "9999999999999999", 80, false},
+		{"0", "0", 0, false},
+		{"-0.000", "0", 0, false},
+	}
+
+	for _, c := range cases {
+		mantissa, offset, negative, err := normalizeIOU(c.value)
+		if err != nil {
+			t.Fatalf("normalizeIOU %s fail : %s", c.value, err.Error())
+		}
+
+		if mantissa.String() != c.mantissa || offset != c.offset || negative != c.negative {
+			t.Fatalf("normalizeIOU %s : %s %d %v, expect %s %d %v", c.value, mantissa, offset, negative, c.mantissa, c.offset, c.negative)
+		}
+	}
+
+	for _, value := range []string{"", ".", "abc", "1.2.3", "12345678901234567", "1e-97", "1e96"} {
+		if _, _, _, err := normalizeIOU(value); err == nil {
+			t.Fatalf("normalizeIOU %s should fail", value)
+		}
+	}
+}
+
+func Test_SerializeIOU(t *testing.T) {
+	cases := map[string]string{
+		"1":    "D4838D7EA4C68000",
+		"-0.1": "94438D7EA4C68000",
+		"0":    "8000000000000000",
+	}
+
+	for value, expect := range cases {
+		so := new(Serializer)
+		new(SerializedAmount).Serialize(so, constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: value}, false)
+		if so.err != nil {
+			t.Fatalf("Serialize %s fail : %s", value, so.err.Error())
+		}
+
+		if hex := strings.ToUpper(so.ToHex()); hex[:16] != expect {
+			t.Fatalf("Serialize %s : %s, expect %s", value, hex[:16], expect)
+		}
+	}
+}
+
+func Test_AmountArithmetic(t *testing.T) {
+	cny := func(value string) *TumAmount {
+		amount, err := ParseAmount(constant.Amount{Currency: "CNY", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: value})
+		if err != nil {
+			t.Fatalf("ParseAmount %s fail : %s", value, err.Error())
+		}
+		return amount
+	}
+
+	sum, err := cny("0.1").Add(cny("0.2"))
+	if err != nil || sum.ToAmount().Value != "0.3" {
+		t.Fatalf("0.1 + 0.2 = %v, %v", sum.ToAmount(), err)
+	}
+
+	diff, err := cny("0.1").Subtract(cny("0.3"))
+	if err != nil || diff.ToAmount().Value != "-0.2" {
+		t.Fatalf("0.1 - 0.3 = %v, %v", diff.ToAmount(), err)
+	}
+
+	if diff.Negate().ToAmount().Value != "0.2" {
+		t.Fatalf("Negate %v", diff.Negate().ToAmount())
+	}
+
+	quotient, err := cny("1").Divide(big.NewRat(3, 1))
+	if err != nil || quotient.ToAmount().Value != "0.3333333333333333" {
+		t.Fatalf("1 / 3 = %v, %v", quotient.ToAmount(), err)
+	}
+
+	if c, err := cny("10").Compare(cny("9.99")); err != nil || c != 1 {
+		t.Fatalf("Compare %d, %v", c, err)
+	}
+
+	swt, err := AmountFromDrops("1500000")
+	if err != nil {
+		t.Fatalf("AmountFromDrops fail : %s", err.Error())
+	}
+
+	if _, err := swt.Add(cny("1")); err == nil {
+		t.Fatalf("Add different currencies should fail")
+	}
+
+	usd, _ := ParseAmount(constant.Amount{Currency: "USD", Issuer: "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Value: "1"})
+	if _, err := usd.Compare(cny("1")); err == nil {
+		t.Fatalf("Compare different currencies should fail")
+	}
+
+	//数量乘以价格，结果为数量的货币
+	total, err := swt.Multiply(big.NewRat(1, 2))
+	if drops, _ := total.Drops(); err != nil || drops != "750000" {
+		t.Fatalf("1.5 SWT * 0.5 = %s, %v", drops, err)
+	}
+
+	third, _ := swt.Divide(big.NewRat(7, 1))
+	if drops, _ := third.Drops(); drops != "214286" {
+		t.Fatalf("1.5 SWT / 7 = %s drops, expect 214286", drops)
+	}
+
+	//本币以 drops 计，与 Quality 相同
+	ratio, err := cny("3").Ratio(swt)
+	if err != nil || ratio.RatString() != "1/500000" {
+		t.Fatalf("Ratio %v, %v", ratio, err)
+	}
+
+	quality, err := Quality(cny("3"), swt)
+	if err != nil || quality != "0.000002" {
+		t.Fatalf("Quality %s, %v", quality, err)
+	}
+
+	if _, err := cny("1").Divide(new(big.Rat)); err == nil {
+		t.Fatalf("Division by zero should fail")
+	}
+}
+
+//Test_NativeCurrencyRoundTrip 未加载配置时本币为 SWT，ToAmount 的结果可由 ParseAmount 解析回来
+func Test_NativeCurrencyRoundTrip(t *testing.T) {
+	saved := constant.CFGCurrency
+	defer func() { constant.CFGCurrency = saved }()
+	constant.CFGCurrency = ""
+
+	swt, err := AmountFromDrops("1500000")
+	if err != nil {
+		t.Fatalf("AmountFromDrops fail : %s", err.Error())
+	}
+
+	amount := swt.ToAmount()
+	if amount.Currency != "SWT" || amount.Value != "1.5" {
+		t.Fatalf("ToAmount %v", amount)
+	}
+
+	parsed, err := ParseAmount(amount)
+	if err != nil {
+		t.Fatalf("ParseAmount %v fail : %s", amount, err.Error())
+	}
+
+	if c, err := parsed.Compare(swt); err != nil || c != 0 || !parsed.IsNative {
+		t.Fatalf("ParseAmount %v : %v, %v", amount, parsed.ToAmount(), err)
+	}
+}
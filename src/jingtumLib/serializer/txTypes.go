@@ -0,0 +1,361 @@
+/**
+ *
+ * 强类型交易结构体，通过结构体标签 jingtum:"字段名[,omitempty]" 序列化
+ *
+ * @FileName: txTypes.go
+ */
+
+package serializer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"jingtumlib/constant"
+)
+
+//TxData 强类型交易
+type TxData interface {
+	//TxType 交易类型，如 Payment
+	TxType() string
+	//Common 交易公共字段
+	Common() *TxCommon
+}
+
+//txValuer 自定义字段类型转换成序列化所需的值
+type txValuer interface {
+	txValue() interface{}
+}
+
+//errNilTx 强类型交易为 nil
+var errNilTx = errors.New("Transaction is nil")
+
+//Drops 以 drops 表示的 SWT 金额，1 SWT = 1000000 drops
+type Drops uint64
+
+func (drops Drops) txValue() interface{} {
+	return strconv.FormatUint(uint64(drops), 10)
+}
+
+//Memos 备注列表，MemoData 为原文
+type Memos []MemoInfo
+
+func (memos Memos) txValue() interface{} {
+	items := make([]interface{}, 0, len(memos))
+	for _, memo := range memos {
+		items = append(items, map[string]interface{}{"Memo": memo.Memo})
+	}
+	return items
+}
+
+//Args 合约参数列表，参数为16进制字符串
+type Args []string
+
+func (args Args) txValue() interface{} {
+	items := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		items = append(items, map[string]interface{}{"Arg": map[string]interface{}{"Parameter": arg}})
+	}
+	return items
+}
+
+//TxCommon 交易公共字段
+type TxCommon struct {
+	Flags              uint32 `jingtum:"Flags"`
+	SourceTag          uint32 `jingtum:"SourceTag,omitempty"`
+	LastLedgerSequence uint32 `jingtum:"LastLedgerSequence,omitempty"`
+	Account            string `jingtum:"Account"`
+	Sequence           uint32 `jingtum:"Sequence,omitempty"`
+	Fee                Drops  `jingtum:"Fee"`
+	SigningPubKey      string `jingtum:"SigningPubKey,omitempty"`
+	TxnSignature       string `jingtum:"TxnSignature,omitempty"`
+	Memos              Memos  `jingtum:"Memos,omitempty"`
+}
+
+//Common 交易公共字段
+func (common *TxCommon) Common() *TxCommon {
+	return common
+}
+
+//Payment 支付
+type Payment struct {
+	TxCommon
+	Destination    string           `jingtum:"Destination"`
+	Amount         constant.Amount  `jingtum:"Amount"`
+	SendMax        *constant.Amount `jingtum:"SendMax,omitempty"`
+	Paths          [][]PathComputed `jingtum:"Paths,omitempty"`
+	InvoiceID      string           `jingtum:"InvoiceID,omitempty"`
+	DestinationTag uint32           `jingtum:"DestinationTag,omitempty"`
+}
+
+//TxType 交易类型
+func (tx *Payment) TxType() string {
+	return "Payment"
+}
+
+//OfferCreate 挂单
+type OfferCreate struct {
+	TxCommon
+	TakerPays  constant.Amount `jingtum:"TakerPays"`
+	TakerGets  constant.Amount `jingtum:"TakerGets"`
+	Expiration uint32          `jingtum:"Expiration,omitempty"`
+}
+
+//TxType 交易类型
+func (tx *OfferCreate) TxType() string {
+	return "OfferCreate"
+}
+
+//OfferCancel 取消挂单
+type OfferCancel struct {
+	TxCommon
+	OfferSequence uint32 `jingtum:"OfferSequence"`
+}
+
+//TxType 交易类型
+func (tx *OfferCancel) TxType() string {
+	return "OfferCancel"
+}
+
+//TrustSet 设置信任
+type TrustSet struct {
+	TxCommon
+	LimitAmount constant.Amount `jingtum:"LimitAmount"`
+	QualityIn   uint32          `jingtum:"QualityIn,omitempty"`
+	QualityOut  uint32          `jingtum:"QualityOut,omitempty"`
+}
+
+//TxType 交易类型
+func (tx *TrustSet) TxType() string {
+	return "TrustSet"
+}
+
+//RelationSet 设置关系（授权、冻结）
+type RelationSet struct {
+	TxCommon
+	Target       string          `jingtum:"Target"`
+	RelationType uint32          `jingtum:"RelationType"`
+	LimitAmount  constant.Amount `jingtum:"LimitAmount"`
+}
+
+//TxType 交易类型
+func (tx *RelationSet) TxType() string {
+	return "RelationSet"
+}
+
+//AccountSet 设置账号属性，Domain 为16进制字符串
+type AccountSet struct {
+	TxCommon
+	SetFlag      uint32 `jingtum:"SetFlag,omitempty"`
+	ClearFlag    uint32 `jingtum:"ClearFlag,omitempty"`
+	TransferRate uint32 `jingtum:"TransferRate,omitempty"`
+	Domain       string `jingtum:"Domain,omitempty"`
+}
+
+//TxType 交易类型
+func (tx *AccountSet) TxType() string {
+	return "AccountSet"
+}
+
+//SetRegularKey 设置关联密钥
+type SetRegularKey struct {
+	TxCommon
+	RegularKey string `jingtum:"RegularKey"`
+}
+
+//TxType 交易类型
+func (tx *SetRegularKey) TxType() string {
+	return "SetRegularKey"
+}
+
+//ConfigContract 部署（Method 为 0）或执行（Method 为 1）合约，Payload、ContractMethod 为16进制字符串
+type ConfigContract struct {
+	TxCommon
+	Method         uint32           `jingtum:"Method"`
+	Payload        string           `jingtum:"Payload,omitempty"`
+	Destination    string           `jingtum:"Destination,omitempty"`
+	Amount         *constant.Amount `jingtum:"Amount,omitempty"`
+	ContractMethod string           `jingtum:"ContractMethod,omitempty"`
+	Args           Args             `jingtum:"Args,omitempty"`
+}
+
+//TxType 交易类型
+func (tx *ConfigContract) TxType() string {
+	return "ConfigContract"
+}
+
+//FromTx 强类型交易序列化，按结构体标签取值。每个类型的标签只解析一次，解析时校验字段名是否属于该交易类型
+func FromTx(tx TxData) (*Serializer, error) {
+	if tx == nil {
+		return nil, errNilTx
+	}
+
+	txType := tx.TxType()
+	typeInt, ok := txTypeStrMapNumber[txType]
+	if !ok {
+		return nil, fmt.Errorf("TransactionType (%s) invalid", txType)
+	}
+
+	fields, err := txPlan(reflect.TypeOf(tx), txType, transactionTypes[typeInt])
+	if err != nil {
+		return nil, err
+	}
+
+	txData := make(map[string]interface{}, len(fields)+1)
+	if err := txFields(reflect.ValueOf(tx), fields, txData); err != nil {
+		return nil, err
+	}
+	txData["TransactionType"] = uint16(typeInt)
+
+	so := newSerializer()
+	so.dropsAmount = true
+	so.Serialize(transactionTypes[typeInt], txData)
+	if so.err != nil {
+		err := so.err
+		so.Release()
+		return nil, err
+	}
+
+	return so, nil
+}
+
+//txField 结构体标签 jingtum:"字段名[,omitempty]" 对应的字段，index 为字段在结构体（含嵌入结构体）中的位置
+type txField struct {
+	index     []int
+	name      string
+	omitEmpty bool
+}
+
+//txPlans 各交易类型的字段，以 reflect.Type 为键
+var txPlans sync.Map
+
+//txPlan 取出类型 t 的字段，首次使用时解析结构体标签，并校验字段都在交易模板 typedef 中
+func txPlan(t reflect.Type, txType string, typedef [][]interface{}) ([]txField, error) {
+	if plan, ok := txPlans.Load(t); ok {
+		return plan.([]txField), nil
+	}
+
+	st := t
+	for st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Transaction must be a struct. Actual type : %s", t)
+	}
+
+	fields := parseTxFields(st, nil)
+	var notAllowed []string
+	for _, field := range fields {
+		if _, ok := fieldRequirement(typedef, field.name); !ok {
+			notAllowed = append(notAllowed, field.name)
+		}
+	}
+	if len(notAllowed) > 0 {
+		return nil, &ValidationError{Type: txType, NotAllowed: notAllowed}
+	}
+
+	txPlans.Store(t, fields)
+	return fields, nil
+}
+
+//parseTxFields 解析结构体标签，嵌入的结构体（如 TxCommon）展开处理
+func parseTxFields(t reflect.Type, index []int) []txField {
+	var fields []txField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, parseTxFields(ft, fieldIndex)...)
+				continue
+			}
+		}
+
+		tag := field.Tag.Get("jingtum")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		fields = append(fields, txField{index: fieldIndex, name: options[0], omitEmpty: len(options) > 1 && options[1] == "omitempty"})
+	}
+
+	return fields
+}
+
+//txFields 按 fields 取出字段值
+func txFields(v reflect.Value, fields []txField, txData map[string]interface{}) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return errNilTx
+		}
+		v = v.Elem()
+	}
+
+	for _, field := range fields {
+		value, ok := fieldByIndex(v, field.index)
+		if !ok {
+			return errNilTx
+		}
+
+		if field.omitEmpty && isZeroField(value) {
+			continue
+		}
+
+		switch fv := value.Interface().(type) {
+		case txValuer:
+			txData[field.name] = fv.txValue()
+		case *constant.Amount:
+			if fv != nil {
+				txData[field.name] = *fv
+			}
+		default:
+			txData[field.name] = fv
+		}
+	}
+
+	return nil
+}
+
+//fieldByIndex 同 reflect.Value.FieldByIndex，嵌入的结构体指针为 nil 时返回 false
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+//isZeroField omitempty 的字段是否为零值，nil 以外的空切片不是零值
+func isZeroField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
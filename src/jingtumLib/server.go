@@ -10,34 +10,64 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"jingtumlib/constant"
 	"jingtumlib/utils"
-
-	"github.com/caivega/evtwebsocket"
 )
 
 //Server 区块链网络通信服务结构体。
 type Server struct {
-	id        uint64
-	remote    *Remote
-	connected bool
-	opened    bool
-	state     string
-	conn      *evtwebsocket.Conn
-	opts      map[string]interface{}
-	url       string
-	reqs      chan *ReqCtx
-	l         *sync.RWMutex
-	wg        *sync.WaitGroup
+	remote          *Remote
+	connected       bool
+	opened          bool
+	state           string
+	transport       Transport
+	opts            map[string]interface{}
+	url             string
+	reqs            chan *ReqCtx
+	l               *sync.RWMutex
+	wg              *sync.WaitGroup
+	policy          *ReconnectPolicy
+	closing         bool
+	done            chan struct{}
+	latency         time.Duration
+	errors          int
+	serverState     string
+	completeLedgers string
+	ledgerIndex     uint64
+}
+
+//ReconnectPolicy 断线重连策略。第 n 次重连前等待 MinDelay * 2^(n-1)，最长 MaxDelay，
+//实际等待时间随机减少至多一半（jitter），避免大量客户端在节点恢复时同时重连
+type ReconnectPolicy struct {
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int //最多重连次数，0 为不限次数
+}
+
+//DefaultReconnectPolicy 默认重连策略，从 1 秒开始每次加倍，最长 1 分钟，不限次数
+var DefaultReconnectPolicy = ReconnectPolicy{MinDelay: time.Second, MaxDelay: time.Minute}
+
+//ConnectionEvent 连接状态事件，EventConnecting、EventOnline 和 EventOffline 事件的数据
+type ConnectionEvent struct {
+	State   string        //connecting、online 或 offline
+	URL     string        //服务地址
+	Attempt int           //第几次重连，首次连接和节点状态变化为 0
+	Delay   time.Duration //本次重连前等待的时间
+	Err     error         //断开或重连失败的原因
 }
 
 type activeStates []string
 
+//defaultStreams 连接后默认订阅的消息
+var defaultStreams = []string{"transactions", "ledger", "server"}
+
 var (
 	onlineStates = activeStates{"syncing", "tracking", "proposing", "validating", "full", "connected"}
 	domainRE     = "[A-Za-z0-9]+(\\.[A-Za-z0-9]){1,5}" //"^(?=.{1,255}$)[0-9A-Za-z](?:(?:[0-9A-Za-z]|[-_]){0,61}[0-9A-Za-z])?(?:\\.[0-9A-Za-z](?:(?:[0-9A-Za-z]|[-_]){0,61}[0-9A-Za-z])?)*\\.?$"
@@ -84,65 +114,112 @@ func NewServer(remote *Remote, urlStr string) (*Server, error) {
 	server.opts["port"] = iport
 	server.opts["protocol"] = urlParsed.Scheme
 
-	if urlParsed.Scheme == "wss" {
+	if urlParsed.Scheme == "wss" || urlParsed.Scheme == "https" {
 		server.opts["secure"] = true
 	} else {
 		server.opts["secure"] = false
 	}
 
-	if server.opts["secure"].(bool) {
-		server.url = "wss://" + server.opts["host"].(string) + ":" + urlParsed.Port()
-	} else {
-		server.url = "ws://" + server.opts["host"].(string) + ":" + urlParsed.Port()
+	//未注册传输层的 scheme 按 websocket 连接
+	scheme := "ws"
+	if hasTransport(urlParsed.Scheme) {
+		scheme = urlParsed.Scheme
 	}
+	server.url = scheme + "://" + server.opts["host"].(string) + ":" + urlParsed.Port()
 
-	server.id = 0
 	server.remote = remote
-	server.state = "offline"
+	server.state = constant.EventOffline
 	server.connected = false
 	server.opened = false
 	server.l = new(sync.RWMutex)
 	server.reqs = make(chan *ReqCtx)
 	server.wg = &sync.WaitGroup{}
+	policy := DefaultReconnectPolicy
+	server.policy = &policy
 	return server, nil
 }
 
-//Disconnect 关闭连接
+//delay 第 attempt 次重连前的等待时间
+func (policy *ReconnectPolicy) delay(attempt int) time.Duration {
+	delay := policy.MinDelay
+	for i := 1; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay - time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+//Disconnect 关闭连接，并停止断线重连
 func (server *Server) Disconnect() bool {
-	if server == nil || !server.connected {
+	if server == nil {
 		return true
 	}
 
-	req := server.remote.UnSubscribe([]string{"transactions", "ledger", "server"})
-	server.wg.Add(2)
-	req.Submit(func(err error, result interface{}) {
-		// log.Println("Unsubscribe result : ", result, err)
-		server.wg.Done()
-	})
+	server.l.Lock()
+	if server.done == nil || server.closing {
+		server.l.Unlock()
+		return true
+	}
+	server.closing = true
+	close(server.done)
+	connected := server.connected && server.transport != nil && server.transport.Subscribable()
+	server.l.Unlock()
+
+	if connected {
+		server.wg.Add(1)
+		server.remote.send(server, newReqCtx(constant.CommandUnSubscribe, map[string]interface{}{"streams": defaultStreams}, func(err error, result interface{}) {
+			// log.Println("Unsubscribe result : ", result, err)
+			server.wg.Done()
+		}))
+	}
+	server.remote.emit.Off("*")
+	server.stopSending()
+
+	server.l.RLock()
+	transport := server.transport
+	server.l.RUnlock()
+	if transport != nil {
+		transport.Close()
+	}
+	// close(server.reqs)
+	server.setState(constant.EventOffline)
+	return true
+}
+
+//stopSending 终止消息发送线程，等待已提交的请求发送完
+func (server *Server) stopSending() {
+	server.wg.Add(1)
 	rc := new(ReqCtx)
 	rc.command = constant.CommandDisconnect
 	server.sendMessage(rc)
-	server.remote.emit.Off("*")
 	server.wg.Wait()
-	server.conn.Close()
-	// close(server.reqs)
-	server.state = "offline"
-	server.connected = false
-	server.opened = false
-	return true
+}
+
+//started 已调用 connect 且没有关闭
+func (server *Server) started() bool {
+	server.l.RLock()
+	defer server.l.RUnlock()
+	return server.done != nil && !server.closing
 }
 
 //IsConnected true已连接。
 func (server *Server) IsConnected() bool {
+	server.l.RLock()
+	defer server.l.RUnlock()
 	return server.connected
 }
 
-//GetCid 每次请求序列递增。
+//GetCid 每次请求序列递增，同一 Remote 的节点共用序列。
 func (server *Server) GetCid() uint64 {
-	server.l.Lock()
-	defer server.l.Unlock()
-	server.id++
-	return server.id
+	return server.remote.nextCid()
 }
 
 func (server *Server) sendMessage(reqCtx *ReqCtx) {
@@ -153,15 +230,26 @@ func (server *Server) sendMessage(reqCtx *ReqCtx) {
 }
 
 func (server *Server) setState(state string) {
-	if state == server.state {
+	server.changeState(ConnectionEvent{State: state})
+}
+
+//changeState 更新连接状态，状态变化时触发同名事件
+func (server *Server) changeState(event ConnectionEvent) {
+	server.l.Lock()
+	if event.State == server.state {
+		server.l.Unlock()
 		return
 	}
-	server.state = state
-	server.connected = (state == "online")
+	server.state = event.State
+	server.connected = (event.State == constant.EventOnline)
 
 	if !server.connected {
 		server.opened = false
 	}
+	server.l.Unlock()
+
+	event.URL = server.url
+	go server.remote.emit.Emit(event.State, event)
 }
 
 func (server *Server) listeningSend() {
@@ -177,6 +265,10 @@ func (server *Server) listeningSend() {
 			break
 		}
 
+		if !server.remote.isPending(req, server) {
+			continue
+		}
+
 		req.data["id"] = req.cid
 		req.data["command"] = req.command
 		jsonData, err := json.Marshal(req.data)
@@ -185,81 +277,297 @@ func (server *Server) listeningSend() {
 			continue
 		}
 
-		fmt.Printf("Request info %s\n", jsonData)
-		bm := evtwebsocket.Msg{
-			Body: jsonData,
-			Callback: func(msg []byte, w *evtwebsocket.Conn) {
-				// fmt.Printf("Response message : %s\n", msg)
-			},
+		// 发送消息，断线时请求可能已经由 failRequests 结束
+		server.l.RLock()
+		transport := server.transport
+		server.l.RUnlock()
+		req.sent = time.Now()
+		cid := req.cid
+		if transport == nil {
+			err = constant.ERR_SERVER_NOT_READY
+		} else {
+			err = transport.Send(req.ctx, jsonData, func(err error) {
+				server.requestFailed(cid, err)
+			})
 		}
-
-		// 发送消息
-		if err := server.conn.Send(bm); err != nil {
-			// server.Disconnect()
-			req.callback(err, nil)
-			break
+		if err != nil {
+			server.requestFailed(cid, err)
 		}
 	}
 }
 
-func (server *Server) connect(callback func(err error, result interface{})) error {
-	if server.connected {
+//connect 连接节点。retry 为 true 时（节点池）首次连接失败也按重连策略在后台重连
+func (server *Server) connect(callback func(err error, result interface{}), retry bool) error {
+	if server.IsConnected() {
 		return nil
 	}
 
-	if server.conn != nil {
+	if server.started() {
 		server.Disconnect()
 	}
 
+	server.l.Lock()
+	server.closing = false
+	server.done = make(chan struct{})
+	done := server.done
+	policy := server.policy
+	server.l.Unlock()
+
+	go server.listeningSend()
+	if len(server.remote.servers) > 1 {
+		go server.healthCheck(done)
+	}
+
 	var once sync.Once
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 
-	server.conn = &evtwebsocket.Conn{
-
-		OnConnected: func(w *evtwebsocket.Conn) {
-			server.connected = true
-			server.opened = true
-			server.state = "online"
-			connectMsg := fmt.Sprintf("Connect to [%s] success.", server.url)
-			once.Do(wg.Done)
-			callback(nil, connectMsg)
-
-			go func() {
-				req := server.remote.Subscribe([]string{"transactions", "ledger", "server"})
-				req.Submit(func(err error, result interface{}) {
-				})
-			}()
-		},
+	server.setState(constant.EventConnecting)
+	err := server.dial(func() {
+		once.Do(func() {
+			wg.Done()
+			callback(nil, fmt.Sprintf("Connect to [%s] success.", server.url))
+		})
+	})
+	if err != nil {
+		server.l.Lock()
+		server.transport = nil
+		server.l.Unlock()
+		server.changeState(ConnectionEvent{State: constant.EventOffline, Err: err})
+		if retry && policy != nil {
+			go server.reconnect(*policy, done)
+		} else {
+			server.l.Lock()
+			server.closing = true
+			close(done)
+			server.l.Unlock()
+			server.stopSending()
+		}
+		callback(err, nil)
+		return err
+	}
 
-		OnMessage: func(msg []byte, w *evtwebsocket.Conn) {
-			// fmt.Printf("On message %s\n", msg)
-			server.remote.handleMessage(msg)
-		},
+	wg.Wait()
 
-		MatchMsg: func(req, resp []byte) bool {
-			return true
-		},
+	return nil
+}
 
-		OnError: func(err error) {
-			log.Printf("On error : %s", err.Error())
-		},
+//dial 通过传输层建立新的连接。每次重连都创建新的传输层，旧连接迟到的回调不会影响当前连接
+func (server *Server) dial(onConnected func()) error {
+	transport, err := newTransport(server.url)
+	if err != nil {
+		return err
+	}
 
-		Reconnect: false,
+	server.l.Lock()
+	if server.closing {
+		server.l.Unlock()
+		return constant.ERR_SERVER_NOT_READY
 	}
-	err := server.conn.Dial(server.url, "")
+	server.transport = transport
+	server.l.Unlock()
+
+	var dropped sync.Once
+	err = transport.Dial(func(msg []byte) {
+		server.remote.handleMessage(server, msg)
+	}, func(err error) {
+		dropped.Do(func() {
+			server.onDisconnected(transport, err)
+		})
+	})
 	if err != nil {
-		callback(err, nil)
 		return err
 	}
 
-	go server.listeningSend()
-
-	wg.Wait()
+	//重连过程中调用了 Disconnect
+	server.l.RLock()
+	closing := server.closing
+	server.l.RUnlock()
+	if closing {
+		transport.Close()
+		return nil
+	}
 
+	go server.onConnected(transport, onConnected)
 	return nil
 }
 
+//onConnected 连接成功：订阅默认消息和断线前订阅的消息，节点池中同时检查节点状态
+func (server *Server) onConnected(transport Transport, callback func()) {
+	if !server.isCurrent(transport) {
+		return
+	}
+
+	server.l.Lock()
+	server.opened = true
+	server.l.Unlock()
+	server.setState(constant.EventOnline)
+	if callback != nil {
+		callback()
+	}
+
+	if transport.Subscribable() {
+		server.remote.send(server, newReqCtx(constant.CommandSubscribe, map[string]interface{}{"streams": server.remote.activeStreams()}, func(err error, result interface{}) {
+		}))
+	}
+	if len(server.remote.servers) > 1 {
+		server.probe()
+	}
+}
+
+func (server *Server) isCurrent(transport Transport) bool {
+	server.l.RLock()
+	defer server.l.RUnlock()
+	return transport == server.transport
+}
+
+//isSubscribable 在线且传输层支持订阅
+func (server *Server) isSubscribable() bool {
+	server.l.RLock()
+	defer server.l.RUnlock()
+	return server.connected && server.transport != nil && server.transport.Subscribable()
+}
+
+//onDisconnected 连接断开：结束未收到响应的请求，不是 Disconnect 关闭的连接按重连策略重连
+func (server *Server) onDisconnected(transport Transport, err error) {
+	server.l.Lock()
+	current := transport == server.transport
+	if current {
+		server.transport = nil
+	}
+	closing := server.closing
+	done := server.done
+	policy := server.policy
+	server.l.Unlock()
+
+	if !current {
+		return
+	}
+
+	server.changeState(ConnectionEvent{State: constant.EventOffline, Err: err})
+	server.recordError()
+	server.remote.failRequests(server, constant.ERR_SERVER_DISCONNECTED)
+
+	if !closing && policy != nil {
+		go server.reconnect(*policy, done)
+	}
+}
+
+//reconnect 按指数退避重连，直到连接成功、达到最多重连次数或调用 Disconnect
+func (server *Server) reconnect(policy ReconnectPolicy, done chan struct{}) {
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		delay := policy.delay(attempt)
+		select {
+		case <-done:
+			return
+		case <-time.After(delay):
+		}
+
+		server.changeState(ConnectionEvent{State: constant.EventConnecting, Attempt: attempt, Delay: delay})
+		err := server.dial(nil)
+		if err == nil {
+			return
+		}
+
+		log.Printf("Reconnect to [%s] fail : %s", server.url, err.Error())
+		server.changeState(ConnectionEvent{State: constant.EventOffline, Attempt: attempt, Err: err})
+	}
+}
+
+//setReconnectPolicy 设置重连策略，nil 为不重连
+func (server *Server) setReconnectPolicy(policy *ReconnectPolicy) {
+	server.l.Lock()
+	defer server.l.Unlock()
+	if policy == nil {
+		server.policy = nil
+		return
+	}
+
+	p := *policy
+	server.policy = &p
+}
+
+//healthCheck 节点池中定时请求 server_info，更新节点状态和响应时间
+func (server *Server) healthCheck(done chan struct{}) {
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if server.IsConnected() {
+				server.probe()
+			}
+		}
+	}
+}
+
+//probe 请求 server_info 更新节点状态
+func (server *Server) probe() {
+	server.remote.send(server, newReqCtx(constant.CommandServerInfo, map[string]interface{}{}, func(err error, result interface{}) {
+		if err != nil {
+			return
+		}
+
+		info, ok := result.(map[string]interface{})["info"].(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		data := ResData(info)
+		server.l.Lock()
+		server.serverState = data.getString("server_state")
+		server.completeLedgers = data.getString("complete_ledgers")
+		if ledger := ResData(data.getMap("validated_ledger")); ledger != nil && ledger.getUint64("seq") > server.ledgerIndex {
+			server.ledgerIndex = ledger.getUint64("seq")
+		}
+		server.l.Unlock()
+	}))
+}
+
+//recordLatency 记录请求响应时间（滑动平均），成功响应后清零失败次数
+func (server *Server) recordLatency(latency time.Duration) {
+	server.l.Lock()
+	defer server.l.Unlock()
+	if server.latency == 0 {
+		server.latency = latency
+	} else {
+		server.latency = (server.latency*4 + latency) / 5
+	}
+	server.errors = 0
+}
+
+//requestFailed 请求未送达或失败：记录错误，转移到其他节点或以 err 结束请求
+func (server *Server) requestFailed(cid uint64, err error) {
+	server.recordError()
+	if rc, ok := server.remote.removeRequest(cid); ok {
+		server.remote.failover(rc, err)
+	}
+}
+
+//recordError 记录发送失败、断线等错误
+func (server *Server) recordError() {
+	server.l.Lock()
+	defer server.l.Unlock()
+	server.errors++
+}
+
+//updateLedger 根据账本消息和节点状态消息更新节点状态
+func (server *Server) updateLedger(data ResData) {
+	server.l.Lock()
+	defer server.l.Unlock()
+	if data.getString("server_status") != "" {
+		server.serverState = data.getString("server_status")
+	}
+	if data.getString("validated_ledgers") != "" {
+		server.completeLedgers = data.getString("validated_ledgers")
+	}
+	if index := data.getUint64("ledger_index"); index > server.ledgerIndex {
+		server.ledgerIndex = index
+	}
+}
+
 func (status activeStates) contain(value string) bool {
 	return status.indexOf(value) >= 0
 }
@@ -0,0 +1,246 @@
+/**
+ * 底层通信服务测试类，用本地 websocket 模拟节点，测试断线重连
+ *
+ * @FileName: server_test.go
+ */
+package jingtumlib
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"jingtumlib/constant"
+
+	"golang.org/x/net/websocket"
+)
+
+//mockNode 模拟节点，记录收到的请求，hold 中的请求不响应（模拟等待响应的请求），默认为 ledger_closed
+type mockNode struct {
+	l        sync.Mutex
+	conn     *websocket.Conn
+	requests chan map[string]interface{}
+	hold     map[string]bool
+	delay    time.Duration
+	state    string
+	ledger   uint64
+}
+
+func newMockNode() (*mockNode, *httptest.Server) {
+	node := &mockNode{requests: make(chan map[string]interface{}, 100), hold: map[string]bool{constant.CommandLedgerClosed: true}, state: "full", ledger: 100}
+	return node, httptest.NewServer(websocket.Handler(node.serve))
+}
+
+func (node *mockNode) serve(ws *websocket.Conn) {
+	node.l.Lock()
+	node.conn = ws
+	node.l.Unlock()
+
+	for {
+		var req map[string]interface{}
+		if err := websocket.JSON.Receive(ws, &req); err != nil {
+			return
+		}
+
+		node.requests <- req
+		node.l.Lock()
+		hold, delay := node.hold[req["command"].(string)], node.delay
+		result := map[string]interface{}{}
+		if req["command"] == constant.CommandServerInfo {
+			result["info"] = map[string]interface{}{"server_state": node.state, "complete_ledgers": "1-100", "validated_ledger": map[string]interface{}{"seq": node.ledger}}
+		}
+		node.l.Unlock()
+		if hold {
+			continue
+		}
+
+		time.Sleep(delay)
+		websocket.JSON.Send(ws, map[string]interface{}{"id": req["id"], "type": "response", "status": "success", "result": result})
+	}
+}
+
+//drop 断开当前连接
+func (node *mockNode) drop() {
+	node.l.Lock()
+	defer node.l.Unlock()
+	node.conn.Close()
+}
+
+//push 推送订阅消息
+func (node *mockNode) push(msg map[string]interface{}) {
+	node.l.Lock()
+	defer node.l.Unlock()
+	websocket.JSON.Send(node.conn, msg)
+}
+
+//waitRequest 等待收到 command 请求
+func (node *mockNode) waitRequest(t *testing.T, command string) map[string]interface{} {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case req := <-node.requests:
+			if req["command"] == command {
+				return req
+			}
+		case <-timeout:
+			t.Fatalf("Wait %s request timeout", command)
+			return nil
+		}
+	}
+}
+
+func mockRemote(t *testing.T, srv *httptest.Server) *Remote {
+	remote, err := NewRemote(strings.Replace(srv.URL, "http://", "ws://", 1), true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err.Error())
+	}
+
+	remote.SetReconnectPolicy(&ReconnectPolicy{MinDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond})
+	return remote
+}
+
+//listenStates 记录连接状态事件
+func listenStates(remote *Remote) chan ConnectionEvent {
+	events := make(chan ConnectionEvent, 100)
+	for _, name := range []string{constant.EventConnecting, constant.EventOnline, constant.EventOffline} {
+		remote.On(name, func(data interface{}) {
+			events <- data.(ConnectionEvent)
+		})
+	}
+	return events
+}
+
+//waitState 等待 state 事件
+func waitState(t *testing.T, events chan ConnectionEvent, state string) ConnectionEvent {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.State == state {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("Wait %s event timeout", state)
+			return ConnectionEvent{}
+		}
+	}
+}
+
+//Test_Reconnect 断线后结束等待响应的请求，重连并重新订阅
+func Test_Reconnect(t *testing.T) {
+	node, srv := newMockNode()
+	defer srv.Close()
+
+	remote := mockRemote(t, srv)
+	events := listenStates(remote)
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	defer remote.Disconnect()
+
+	waitState(t, events, constant.EventOnline)
+	node.waitRequest(t, constant.CommandSubscribe)
+
+	subscribed := make(chan error, 1)
+	remote.Subscribe([]string{"peer_status"}).Submit(func(err error, result interface{}) {
+		subscribed <- err
+	})
+	node.waitRequest(t, constant.CommandSubscribe)
+	if err := <-subscribed; err != nil {
+		t.Fatalf("Subscribe fail : %s", err.Error())
+	}
+
+	pending := make(chan error, 1)
+	req, _ := remote.RequestLedgerClosed()
+	req.Submit(func(err error, result interface{}) {
+		pending <- err
+	})
+	node.waitRequest(t, constant.CommandLedgerClosed)
+
+	node.drop()
+	if event := waitState(t, events, constant.EventOffline); event.Err == nil {
+		t.Errorf("Offline event should have error")
+	}
+
+	select {
+	case err := <-pending:
+		if err != constant.ERR_SERVER_DISCONNECTED {
+			t.Errorf("Pending request should fail with ERR_SERVER_DISCONNECTED, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Pending request not failed")
+	}
+
+	if event := waitState(t, events, constant.EventConnecting); event.Attempt != 1 {
+		t.Errorf("Reconnect attempt should be 1, got %d", event.Attempt)
+	}
+	waitState(t, events, constant.EventOnline)
+
+	streams := node.waitRequest(t, constant.CommandSubscribe)["streams"]
+	expected := []interface{}{"transactions", "ledger", "server", "peer_status"}
+	if !reflect.DeepEqual(streams, expected) {
+		t.Errorf("Resubscribe streams should be %v, got %v", expected, streams)
+	}
+
+	if !remote.IsConnected() {
+		t.Errorf("Server should be connected after reconnect")
+	}
+}
+
+//Test_ReconnectDisconnect 节点不可用时持续重连，Disconnect 后停止重连
+func Test_ReconnectDisconnect(t *testing.T) {
+	node, srv := newMockNode()
+	remote := mockRemote(t, srv)
+	events := listenStates(remote)
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	node.waitRequest(t, constant.CommandSubscribe)
+
+	srv.Listener.Close()
+	node.drop()
+	srv.Close()
+
+	for {
+		if event := waitState(t, events, constant.EventOffline); event.Attempt > 0 {
+			break
+		}
+	}
+
+	done := make(chan bool)
+	go func() {
+		remote.Disconnect()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Disconnect while reconnecting timeout")
+	}
+
+	if remote.IsConnected() {
+		t.Errorf("Server should not be connected after disconnect")
+	}
+}
+
+//Test_ReconnectPolicy 重连等待时间按指数增长，不超过 MaxDelay，jitter 至多减少一半
+func Test_ReconnectPolicy(t *testing.T) {
+	policy := ReconnectPolicy{MinDelay: time.Second, MaxDelay: 10 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, max := range expected {
+		for j := 0; j < 100; j++ {
+			delay := policy.delay(i + 1)
+			if delay > max || delay < max/2 {
+				t.Fatalf("Attempt %d delay %s out of [%s, %s]", i+1, delay, max/2, max)
+			}
+		}
+	}
+
+	if delay := (&ReconnectPolicy{}).delay(1); delay != 0 {
+		t.Errorf("Zero policy delay should be 0, got %s", delay)
+	}
+}
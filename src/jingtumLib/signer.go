@@ -0,0 +1,278 @@
+/**
+ * 签名者接口，以及远程签名服务的客户端和服务端。
+ *
+ * @FileName: signer.go
+ */
+package jingtumlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/utils"
+)
+
+//Signer 交易签名者，私钥可以不在本进程中（如远程签名服务、加密机）。Wallet 实现了该接口
+type Signer interface {
+	//GetPublicKey 16 进制的 33 字节公钥，ed25519 公钥以 ED 开头
+	GetPublicKey() string
+	//Sign 对签名数据（哈希前缀 + 序列化数据）签名，返回 16 进制签名。
+	//secp256k1 对签名数据的 sha512 half 哈希签名，ed25519 对签名数据本身签名
+	Sign(message []byte) (string, error)
+}
+
+//signerAddress 签名者公钥对应的地址
+func signerAddress(signer Signer) (string, error) {
+	pubKey, err := hex.DecodeString(signer.GetPublicKey())
+	if err != nil || len(pubKey) != 33 {
+		return "", fmt.Errorf("Invalid signer public key %s", signer.GetPublicKey())
+	}
+
+	return crypto.AddressFromPublicKey(pubKey), nil
+}
+
+//RemoteSigner 远程签名服务（SignerServer）的客户端。
+//secp256k1 密钥只把签名数据的 sha512 half 哈希发给服务端；ed25519 签名需要签名数据本身，发送的是签名数据
+type RemoteSigner struct {
+	client  *http.Client
+	baseURL string
+	address string
+	pubKey  []byte
+}
+
+//signRequest 签名请求，hash 和 message 为 16 进制，只设置其中一个
+type signRequest struct {
+	Address string `json:"address"`
+	Hash    string `json:"hash,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+//signerResponse 签名服务的应答
+type signerResponse struct {
+	PublicKey string `json:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+//NewRemoteSigner 连接远程签名服务并获取 address 的公钥。
+//endpoint 为 unix:///path/to/signer.sock 形式的本地 Unix socket，或 http://127.0.0.1:8001 形式的 HTTP 地址
+func NewRemoteSigner(endpoint string, address string) (*RemoteSigner, error) {
+	if !IsValidAddress(address) {
+		return nil, constant.ERR_INVALID_PARAM
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &RemoteSigner{address: address}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+		signer.client = &http.Client{Transport: transport, Timeout: 10 * time.Second}
+		signer.baseURL = "http://unix"
+	case "http", "https":
+		signer.client = &http.Client{Timeout: 10 * time.Second}
+		signer.baseURL = strings.TrimRight(endpoint, "/")
+	default:
+		return nil, fmt.Errorf("Unsupported signer endpoint %s", endpoint)
+	}
+
+	resp, err := signer.call(http.MethodGet, "/public_key?address="+url.QueryEscape(address), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := hex.DecodeString(resp.PublicKey)
+	if err != nil || len(pubKey) != 33 || crypto.AddressFromPublicKey(pubKey) != address {
+		return nil, fmt.Errorf("Invalid public key %s of %s", resp.PublicKey, address)
+	}
+	signer.pubKey = pubKey
+
+	return signer, nil
+}
+
+//GetPublicKey 获取16进制公钥
+func (signer *RemoteSigner) GetPublicKey() string {
+	return strings.ToUpper(hex.EncodeToString(signer.pubKey))
+}
+
+//GetAddress 获取签名者地址
+func (signer *RemoteSigner) GetAddress() string {
+	return signer.address
+}
+
+//Sign 请求签名服务签名，返回前验证签名，签名无效时返回 constant.ERR_TX_INVALID_SIGNATURE
+func (signer *RemoteSigner) Sign(message []byte) (string, error) {
+	req := signRequest{Address: signer.address}
+	if signer.pubKey[0] == constant.Ed25519PubKeyPrefix {
+		req.Message = hex.EncodeToString(message)
+	} else {
+		sh512 := utils.NewSha512()
+		sh512.Add(message)
+		req.Hash = hex.EncodeToString(sh512.Finish256())
+	}
+
+	resp, err := signer.call(http.MethodPost, "/sign", &req)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := hex.DecodeString(resp.Signature)
+	if err != nil || !verifyKeySignature(signer.pubKey, message, signature, true) {
+		return "", constant.ERR_TX_INVALID_SIGNATURE
+	}
+
+	return strings.ToUpper(resp.Signature), nil
+}
+
+func (signer *RemoteSigner) call(method string, path string, body interface{}) (*signerResponse, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, signer.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := signer.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(signerResponse)
+	if err := json.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("Invalid signer response status %d", res.StatusCode)
+	}
+
+	if res.StatusCode != http.StatusOK || resp.Error != "" {
+		return nil, fmt.Errorf("Signer error : %s", resp.Error)
+	}
+
+	return resp, nil
+}
+
+//SignerServer 远程签名服务，持有钱包私钥，以 HTTP 协议对外提供签名，通常监听本地 Unix socket：
+//
+//	GET  /public_key?address=<地址>   返回 {"public_key": "<16 进制公钥>"}
+//	POST /sign {"address": "<地址>", "hash": "<16 进制 sha512 half 哈希>"}（secp256k1）
+//	     或 {"address": "<地址>", "message": "<16 进制签名数据>"}（ed25519），返回 {"signature": "<16 进制签名>"}
+//
+//出错时返回非 200 状态码和 {"error": "<错误信息>"}
+type SignerServer struct {
+	wallets map[string]*Wallet
+}
+
+//NewSignerServer 创建签名服务
+func NewSignerServer(wallets ...*Wallet) *SignerServer {
+	server := &SignerServer{wallets: make(map[string]*Wallet, len(wallets))}
+	for _, wallet := range wallets {
+		server.wallets[wallet.GetAddress()] = wallet
+	}
+
+	return server
+}
+
+//ServeHTTP 处理签名服务请求
+func (server *SignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/public_key" && r.Method == http.MethodGet:
+		wallet, ok := server.wallets[r.URL.Query().Get("address")]
+		if !ok {
+			writeSignerResponse(w, http.StatusNotFound, &signerResponse{Error: "unknown address"})
+			return
+		}
+
+		writeSignerResponse(w, http.StatusOK, &signerResponse{PublicKey: wallet.GetPublicKey()})
+	case r.URL.Path == "/sign" && r.Method == http.MethodPost:
+		var req signRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeSignerResponse(w, http.StatusBadRequest, &signerResponse{Error: "invalid request"})
+			return
+		}
+
+		wallet, ok := server.wallets[req.Address]
+		if !ok {
+			writeSignerResponse(w, http.StatusNotFound, &signerResponse{Error: "unknown address"})
+			return
+		}
+
+		signature, err := server.sign(wallet, &req)
+		if err != nil {
+			writeSignerResponse(w, http.StatusBadRequest, &signerResponse{Error: err.Error()})
+			return
+		}
+
+		writeSignerResponse(w, http.StatusOK, &signerResponse{Signature: fmt.Sprintf("%X", signature)})
+	default:
+		writeSignerResponse(w, http.StatusNotFound, &signerResponse{Error: "not found"})
+	}
+}
+
+//sign secp256k1 钱包对哈希签名，ed25519 钱包对签名数据签名
+func (server *SignerServer) sign(wallet *Wallet, req *signRequest) ([]byte, error) {
+	if (req.Hash == "") == (req.Message == "") {
+		return nil, errors.New("either hash or message is required")
+	}
+
+	if req.Message != "" {
+		message, err := hex.DecodeString(req.Message)
+		if err != nil {
+			return nil, errors.New("invalid message")
+		}
+
+		return wallet.priv.Sign(message)
+	}
+
+	hashSigner, ok := wallet.priv.(crypto.HashSigner)
+	if !ok {
+		return nil, fmt.Errorf("%s key can not sign hash", wallet.GetKeyType())
+	}
+
+	hash, err := hex.DecodeString(req.Hash)
+	if err != nil {
+		return nil, errors.New("invalid hash")
+	}
+
+	return hashSigner.SignHash(hash)
+}
+
+func writeSignerResponse(w http.ResponseWriter, status int, resp *signerResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
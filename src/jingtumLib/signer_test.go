@@ -0,0 +1,139 @@
+/**
+ * 签名者测试类
+ *
+ * @FileName: signer_test.go
+ */
+package jingtumlib
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"jingtumlib/constant"
+)
+
+//Test_RemoteSigner 通过 Unix socket 上的签名服务签名，私钥不在客户端
+func Test_RemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatalf("TempDir fail : %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Listen fail : %s", err.Error())
+	}
+	defer listener.Close()
+
+	secpWallet, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	edWallet, _ := FromSecret("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j")
+	go http.Serve(listener, NewSignerServer(secpWallet, edWallet))
+
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	for _, wallet := range []*Wallet{secpWallet, edWallet} {
+		signer, err := NewRemoteSigner("unix://"+socket, wallet.GetAddress())
+		if err != nil {
+			t.Fatalf("NewRemoteSigner fail : %s", err.Error())
+		}
+
+		if signer.GetPublicKey() != wallet.GetPublicKey() {
+			t.Fatalf("Public key %s, expect %s", signer.GetPublicKey(), wallet.GetPublicKey())
+		}
+
+		tx, err := remote.BuildPaymentTx(wallet.GetAddress(), "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", Amount{Currency: "SWT", Value: "1"})
+		if err != nil {
+			t.Fatalf("Build payment tx fail : %s", err.Error())
+		}
+		tx.SetSigner(signer)
+		tx.AddTxJSON("Sequence", uint32(50))
+
+		blob, err := signing(tx)
+		if err != nil {
+			t.Fatalf("Signing fail : %s", err.Error())
+		}
+
+		if _, err := VerifyTransaction(blob); err != nil {
+			t.Fatalf("VerifyTransaction fail : %s", err.Error())
+		}
+
+		//多重签名同样可以使用远程签名者
+		multi, err := remote.BuildPaymentTx("j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", Amount{Currency: "SWT", Value: "1"})
+		if err != nil {
+			t.Fatalf("Build payment tx fail : %s", err.Error())
+		}
+		multi.AddTxJSON("Sequence", uint32(8))
+
+		signed, err := multi.SignFor(signer)
+		if err != nil {
+			t.Fatalf("SignFor fail : %s", err.Error())
+		}
+
+		if _, err := CombineSignatures(signed); err != nil {
+			t.Fatalf("CombineSignatures fail : %s", err.Error())
+		}
+	}
+
+	if _, err := NewRemoteSigner("unix://"+socket, "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS"); err == nil {
+		t.Fatalf("Unknown address should fail")
+	}
+
+	if _, err := NewRemoteSigner("ftp://127.0.0.1", secpWallet.GetAddress()); err == nil {
+		t.Fatalf("Unsupported endpoint should fail")
+	}
+}
+
+//Test_SignerServer 签名服务只对 secp256k1 密钥接受哈希
+func Test_SignerServer(t *testing.T) {
+	secpWallet, _ := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	edWallet, _ := FromSecret("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j")
+	server := httptest.NewServer(NewSignerServer(secpWallet, edWallet))
+	defer server.Close()
+
+	hash := strings.Repeat("11", 32)
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"address": "` + secpWallet.GetAddress() + `", "hash": "` + hash + `"}`, http.StatusOK},
+		{`{"address": "` + edWallet.GetAddress() + `", "hash": "` + hash + `"}`, http.StatusBadRequest},
+		{`{"address": "` + edWallet.GetAddress() + `", "message": "5354580012"}`, http.StatusOK},
+		{`{"address": "` + secpWallet.GetAddress() + `", "hash": "1111"}`, http.StatusBadRequest},
+		{`{"address": "` + secpWallet.GetAddress() + `"}`, http.StatusBadRequest},
+		{`{"address": "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS", "hash": "` + hash + `"}`, http.StatusNotFound},
+		{`not json`, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		res, err := http.Post(server.URL+"/sign", "application/json", strings.NewReader(c.body))
+		if err != nil {
+			t.Fatalf("Post fail : %s", err.Error())
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.status {
+			t.Fatalf("%s: status %d, expect %d", c.body, res.StatusCode, c.status)
+		}
+	}
+
+	//签名服务返回的签名与公钥不符时拒绝
+	signer, err := NewRemoteSigner(server.URL, secpWallet.GetAddress())
+	if err != nil {
+		t.Fatalf("NewRemoteSigner fail : %s", err.Error())
+	}
+	signer.pubKey[1] ^= 0xFF
+	if _, err := signer.Sign([]byte("STX message")); err != constant.ERR_TX_INVALID_SIGNATURE {
+		t.Fatalf("Mismatched signature err %v", err)
+	}
+}
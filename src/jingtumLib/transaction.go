@@ -10,9 +10,11 @@ package jingtumlib
 
 import (
 	"container/list"
+	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"math"
+	"math/big"
 	"strings"
 
 	"jingtumlib/constant"
@@ -29,6 +31,12 @@ type Transaction struct {
 	localSign bool
 	secret    string
 	filter    Filter
+	//signer 本地签名的签名者，SetSecret 时为私钥对应的钱包
+	signer Signer
+	//txData 强类型交易，不为空时以它代替 txJSON 签名、提交
+	txData serializer.TxData
+	//prepared txJSON 中的金额、备注已转换成序列化所需的格式
+	prepared bool
 }
 
 //FlagClass FlagClass
@@ -39,7 +47,7 @@ type AccountSet map[string]uint32
 
 var (
 	//TransactionFlags 交易标识
-	TransactionFlags = map[string]FlagClass{"Universal": {"FullyCanonicalSig": 0x00010000}, "AccountSet": {"RequireDestTag": 0x00010000, "OptionalDestTag": 0x00020000, "RequireAuth": 0x00040000, "OptionalAuth": 0x00080000, "DisallowSWT": 0x00100000, "AllowSWT": 0x00200000}, "TrustSet": {"SetAuth": 0x00010000, "NoSkywell": 0x00020000, "SetNoSkywell": 0x00020000, "ClearNoSkywell": 0x00040000, "SetFreeze": 0x00100000, "ClearFreeze": 0x00200000}, "OfferCreate": {"Passive": 0x00010000, "ImmediateOrCancel": 0x00020000, "FillOrKill": 0x00040000, "Sell": 0x00080000}, "Payment": {"NoSkywellDirect": 0x00010000, "PartialPayment": 0x00020000, "LimitQuality": 0x00040000}, "RelationSet": {"Authorize": 0x00000001, "Freeze": 0x00000011}, "RelationDel": {}}
+	TransactionFlags = map[string]FlagClass{"Universal": {"FullyCanonicalSig": constant.TxFlagFullyCanonicalSig}, "AccountSet": {"RequireDestTag": 0x00010000, "OptionalDestTag": 0x00020000, "RequireAuth": 0x00040000, "OptionalAuth": 0x00080000, "DisallowSWT": 0x00100000, "AllowSWT": 0x00200000}, "TrustSet": {"SetAuth": 0x00010000, "NoSkywell": 0x00020000, "SetNoSkywell": 0x00020000, "ClearNoSkywell": 0x00040000, "SetFreeze": 0x00100000, "ClearFreeze": 0x00200000}, "OfferCreate": {"Passive": 0x00010000, "ImmediateOrCancel": 0x00020000, "FillOrKill": 0x00040000, "Sell": 0x00080000}, "Payment": {"NoSkywellDirect": 0x00010000, "PartialPayment": 0x00020000, "LimitQuality": 0x00040000}, "RelationSet": {"Authorize": 0x00000001, "Freeze": 0x00000011}, "RelationDel": {}}
 	//SetClearFlags 清除标识
 	SetClearFlags = map[uint32]AccountSet{uint32(1): {"asfRequireDest": uint32(1), "asfRequireAuth": uint32(2), "asfDisallowSWT": uint32(3), "asfDisableMaster": uint32(4), "asfNoFreeze": uint32(5), "asfGlobalFreeze": uint32(6)}}
 )
@@ -96,12 +104,26 @@ func (tx *Transaction) GetTxJSON(key string) interface{} {
 
 //SetSecret 本地签名时需要设置私钥
 func (tx *Transaction) SetSecret(secret string) {
-	if !IsValidSecret(secret) {
+	wallet, err := FromSecret(secret)
+	if err != nil {
 		tx.AddTxJSON(constant.TxJSONErrorKey, constant.ERR_PAYMENT_INVALID_SECRET)
 		return
 	}
 
 	tx.secret = secret
+	tx.signer = wallet
+}
+
+//SetSigner 设置签名者代替 SetSecret，私钥不需要在本进程中，如 RemoteSigner。
+//设置签名者的交易总是本地签名后提交 blob
+func (tx *Transaction) SetSigner(signer Signer) {
+	if signer == nil {
+		tx.AddTxJSON(constant.TxJSONErrorKey, constant.ERR_EMPTY_PARAM)
+		return
+	}
+
+	tx.secret = ""
+	tx.signer = signer
 }
 
 //AddMemo 设置备注
@@ -132,12 +154,20 @@ func (tx *Transaction) AddMemo(memo string) {
 
 //GetAccount 获得交易账号
 func (tx *Transaction) GetAccount() string {
+	if tx.txData != nil {
+		return tx.txData.Common().Account
+	}
+
 	account, _ := tx.txJSON["Account"].(string)
 	return account
 }
 
 //GetTransactionType 获得交易类型
 func (tx *Transaction) GetTransactionType() string {
+	if tx.txData != nil {
+		return tx.txData.TxType()
+	}
+
 	txType, _ := tx.txJSON["TransactionType"].(string)
 
 	return txType
@@ -152,28 +182,25 @@ func (tx *Transaction) setFee(fee float32) {
 	tx.txJSON["Fee"] = fee
 }
 
+//maxAmount 路径支付的最大金额，在 choice 基础上增加万分之一。字符串形式的 choice 为 drops 表示的本币
 func maxAmount(amount interface{}) (interface{}, error) {
-	if amt, ok := amount.(string); ok {
-		if utils.IsNumberString(amt) {
-			f, err := strconv.ParseFloat(amt, 32)
-			if err != nil {
-				return nil, fmt.Errorf("invalid amount to max %s", amt)
-			}
-
-			return strconv.FormatFloat(f*1.0001, 'f', 10, 32), nil
-		}
+	var choice *serializer.TumAmount
+	var err error
+	if drops, ok := amount.(string); ok {
+		choice, err = serializer.AmountFromDrops(drops)
+	} else {
+		choice, err = serializer.ParseAmount(amount)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount to max %v", amount)
 	}
 
-	if amt, ok := amount.(constant.Amount); ok && utils.IsValidAmount(&amt) {
-		f, err := strconv.ParseFloat(amt.Value, 32)
-		if err != nil {
-			return nil, fmt.Errorf("invalid amount to max %s", amt.Value)
-		}
-		amt.Value = strconv.FormatFloat(f*1.0001, 'f', 10, 32)
-		return amt, nil
+	max, err := choice.Multiply(big.NewRat(10001, 10000))
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("invalid amount to max")
+	return max.ToAmount(), nil
 }
 
 //SetPath 设置支付路径
@@ -245,11 +272,64 @@ func (tx *Transaction) SetFlags(flags interface{}) {
 	}
 }
 
-//signing 签名
+//signing 签名，已签名的交易直接返回原有的 blob，保证交易哈希不变
 func signing(tx *Transaction) (string, error) {
+	if blob, ok := tx.GetTxJSON("blob").(string); ok && tx.localSign {
+		return blob, nil
+	}
+
+	if tx.txData != nil {
+		return signingTxData(tx)
+	}
+
+	if tx.GetTxJSON("TransactionType") == "SignerListSet" {
+		return "", constant.ERR_TX_SERVER_SIGN_ONLY
+	}
+
+	if err := prepareTxJSON(tx); err != nil {
+		return "", err
+	}
+
+	if tx.signer == nil {
+		return "", constant.ERR_TX_SIGNER_REQUIRED
+	}
+
+	tx.setCanonicalFlag()
+	tx.AddTxJSON("SigningPubKey", tx.signer.GetPublicKey())
+	so, err := serializer.FromJSON(tx.txJSON)
+	if err != nil {
+		return "", err
+	}
+	message := so.SigningData(constant.HashPrefixTxSign)
+	so.Release()
+	signTx, err := tx.signer.Sign(message)
+	if err != nil {
+		return "", err
+	}
+
+	tx.AddTxJSON("TxnSignature", signTx)
+	// fmt.Println(signTx)
+	soBlog, err := serializer.FromJSON(tx.txJSON)
+
+	if err != nil {
+		return "", err
+	}
+	// fmt.Println(strings.ToUpper(soBlog.ToHex()))
+	tx.AddTxJSON("blob", strings.ToUpper(soBlog.ToHex()))
+	soBlog.Release()
+	tx.localSign = true
+	return tx.GetTxJSON("blob").(string), nil
+}
+
+//prepareTxJSON 将 SWT 金额转换成序列化所需的单位，备注转成原文，只转换一次
+func prepareTxJSON(tx *Transaction) error {
+	if tx.prepared {
+		return nil
+	}
+
 	fee, ok := decimal.NewFromFloat32(tx.GetTxJSON("Fee").(float32)).Div(decimal.NewFromFloat32(1000000)).Float64()
 	if !ok {
-		return "", fmt.Errorf("Fee / 1000000 float error")
+		return fmt.Errorf("Fee / 1000000 float error")
 	}
 	tx.AddTxJSON("Fee", float32(fee))
 
@@ -258,7 +338,7 @@ func signing(tx *Transaction) (string, error) {
 		if amt64, ok := amount.(float64); ok {
 			amt, ok := decimal.NewFromFloat(amt64).Div(decimal.NewFromFloat(1000000)).Float64() //	NewFromFloat32(tx.GetTxJson("Fee").(float32)).Div(decimal.NewFromFloat32(1000000)).Float64()
 			if !ok {
-				return "", fmt.Errorf("Amount / 1000000 float error")
+				return fmt.Errorf("Amount / 1000000 float error")
 			}
 			tx.AddTxJSON("Amount", amt)
 		}
@@ -275,7 +355,7 @@ func signing(tx *Transaction) (string, error) {
 		if sendMax, ok := tx.GetTxJSON("SendMax").(float64); ok {
 			sm, ok := decimal.NewFromFloat(sendMax).Div(decimal.NewFromFloat(1000000)).Float64()
 			if !ok {
-				return "", fmt.Errorf("SendMax / 1000000 float error")
+				return fmt.Errorf("SendMax / 1000000 float error")
 			}
 			tx.AddTxJSON("SendMax", sm)
 		}
@@ -285,7 +365,7 @@ func signing(tx *Transaction) (string, error) {
 		if takerPays, ok := tx.GetTxJSON("TakerPays").(float64); ok {
 			tp, ok := decimal.NewFromFloat(takerPays).Div(decimal.NewFromFloat(1000000)).Float64()
 			if !ok {
-				return "", fmt.Errorf("TakerPays / 1000000 float error")
+				return fmt.Errorf("TakerPays / 1000000 float error")
 			}
 			tx.AddTxJSON("TakerPays", tp)
 		}
@@ -295,57 +375,137 @@ func signing(tx *Transaction) (string, error) {
 		if takerGets, ok := tx.GetTxJSON("TakerGets").(float64); ok {
 			tg, ok := decimal.NewFromFloat(takerGets).Div(decimal.NewFromFloat(1000000)).Float64()
 			if !ok {
-				return "", fmt.Errorf("TakerGets / 1000000 float error")
+				return fmt.Errorf("TakerGets / 1000000 float error")
 			}
 			tx.AddTxJSON("TakerGets", tg)
 		}
 	}
 
-	wt, err := FromSecret(tx.secret)
-	if err != nil {
-		return "", err
+	tx.prepared = true
+	return nil
+}
+
+//signingTxData 强类型交易签名
+func signingTxData(tx *Transaction) (string, error) {
+	if tx.signer == nil {
+		return "", constant.ERR_TX_SIGNER_REQUIRED
 	}
 
-	tx.AddTxJSON("SigningPubKey", wt.GetPublicKey())
-	var prefix uint32 = 0x53545800
-	so, err := serializer.FromJSON(utils.DeepCopy(tx.txJSON).(map[string]interface{}))
+	tx.setCanonicalFlag()
+	common := tx.txData.Common()
+	common.SigningPubKey = tx.signer.GetPublicKey()
+	common.TxnSignature = ""
+	so, err := serializer.FromTx(tx.txData)
 	if err != nil {
 		return "", err
 	}
-	hash := so.Hash(prefix)
-	// fmt.Println(utils.ByteToHexString(hash))
-	signTx, err := wt.signTx(hash)
+
+	signTx, err := tx.signer.Sign(so.SigningData(constant.HashPrefixTxSign))
+	so.Release()
 	if err != nil {
 		return "", err
 	}
 
-	tx.AddTxJSON("TxnSignature", signTx)
-	// fmt.Println(signTx)
-	soBlog, err := serializer.FromJSON(utils.DeepCopy(tx.txJSON).(map[string]interface{}))
-
+	common.TxnSignature = signTx
+	soBlob, err := serializer.FromTx(tx.txData)
 	if err != nil {
 		return "", err
 	}
-	// fmt.Println(strings.ToUpper(soBlog.ToHex()))
-	tx.AddTxJSON("blob", strings.ToUpper(soBlog.ToHex()))
+
+	tx.AddTxJSON("blob", strings.ToUpper(soBlob.ToHex()))
+	soBlob.Release()
 	tx.localSign = true
 	return tx.GetTxJSON("blob").(string), nil
 }
 
+//setCanonicalFlag 本地签名都是 low-S 的规范签名，设置 FullyCanonicalSig 标志，底层据此拒绝被篡改成 high-S 的签名
+func (tx *Transaction) setCanonicalFlag() {
+	if tx.txData != nil {
+		tx.txData.Common().Flags |= constant.TxFlagFullyCanonicalSig
+		return
+	}
+
+	switch flags := tx.GetTxJSON("Flags").(type) {
+	case uint32:
+		tx.AddTxJSON("Flags", flags|constant.TxFlagFullyCanonicalSig)
+	case int:
+		if flags >= 0 && flags <= math.MaxUint32 {
+			tx.AddTxJSON("Flags", uint32(flags)|constant.TxFlagFullyCanonicalSig)
+		}
+	case float64:
+		if flags >= 0 && flags <= math.MaxUint32 && flags == math.Trunc(flags) {
+			tx.AddTxJSON("Flags", uint32(flags)|constant.TxFlagFullyCanonicalSig)
+		}
+	case nil:
+		tx.AddTxJSON("Flags", constant.TxFlagFullyCanonicalSig)
+	}
+}
+
+//hasSequence 是否已设置 Sequence
+func (tx *Transaction) hasSequence() bool {
+	if tx.txData != nil {
+		return tx.txData.Common().Sequence != 0
+	}
+
+	return tx.GetTxJSON("Sequence") != nil
+}
+
+//setSequence 设置 Sequence
+func (tx *Transaction) setSequence(sequence uint32) {
+	if tx.txData != nil {
+		tx.txData.Common().Sequence = sequence
+		return
+	}
+
+	tx.AddTxJSON("Sequence", sequence)
+}
+
+//Hash 交易哈希（交易 ID）。
+//本地签名的交易尚未签名时，会先用已设置的私钥和 Sequence 完成签名，之后 Submit 提交的是同一个 blob，
+//因此可以在提交前得到交易哈希，并在之后通过 RequestTx 查询。由底层签名的交易在提交前没有哈希，返回错误
+func (tx *Transaction) Hash() (string, error) {
+	if tx.checkTxError() {
+		return "", tx.GetTxJSON(constant.TxJSONErrorKey).(error)
+	}
+
+	blob, ok := tx.GetTxJSON("blob").(string)
+	if !ok {
+		if tx.signer == nil {
+			return "", constant.ERR_TX_SIGNER_REQUIRED
+		}
+
+		if !tx.signsLocally() {
+			return "", constant.ERR_TX_LOCAL_SIGN_REQUIRED
+		}
+
+		if !tx.hasSequence() {
+			return "", fmt.Errorf("Sequence is required to sign transaction")
+		}
+
+		var err error
+		blob, err = signing(tx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return serializer.TransactionID(blob)
+}
+
 //sign 签名方法
-func (tx *Transaction) sign(callback func(err error, blob string)) {
+func (tx *Transaction) sign(ctx context.Context, callback func(err error, blob string)) {
 
-	if tx.GetTxJSON("Sequence") == nil {
+	if !tx.hasSequence() {
 		//从服务端获取 Sequence 后再签名
 		options := make(map[string]interface{})
-		options["account"] = tx.GetTxJSON("Account")
+		options["account"] = tx.GetAccount()
 		options["type"] = "trust"
 		req, err := tx.remote.RequestAccountInfo(options)
 		if err != nil {
 			callback(err, "")
 			return
 		}
-		req.Submit(func(err error, result interface{}) {
+		req.SubmitContext(ctx, func(err error, result interface{}) {
 			if err != nil {
 				callback(err, "")
 				return
@@ -370,7 +530,7 @@ func (tx *Transaction) sign(callback func(err error, blob string)) {
 					return
 				}
 
-				tx.AddTxJSON("Sequence", uint32(decimal.NewFromFloat(seq.(float64)).IntPart()))
+				tx.setSequence(uint32(decimal.NewFromFloat(seq.(float64)).IntPart()))
 				blob, err := signing(tx)
 				if err != nil {
 					callback(err, "")
@@ -394,7 +554,13 @@ func (tx *Transaction) sign(callback func(err error, blob string)) {
 
 //Submit 提交交易数据
 func (tx *Transaction) Submit(callback func(err error, result interface{})) {
-	if !tx.remote.server.IsConnected() {
+	tx.SubmitContext(context.Background(), callback)
+}
+
+//SubmitContext 提交交易数据，ctx 取消或超时时回调 ctx.Err() 或 *TimeoutError。
+//超时后交易仍可能被节点接受，应按交易哈希查询结果
+func (tx *Transaction) SubmitContext(ctx context.Context, callback func(err error, result interface{})) {
+	if !tx.remote.IsConnected() {
 		callback(fmt.Errorf("Server not connected"), nil)
 		return
 	}
@@ -403,27 +569,45 @@ func (tx *Transaction) Submit(callback func(err error, result interface{})) {
 		return
 	}
 
-	if tx.remote.LocalSign {
-		//本地签名
-		tx.sign(func(err error, blob string) {
+	if tx.GetTxJSON("TransactionType") == "Signer" {
+		//已签名（如多重签名）的 blob 直接传给底层，与是否本地签名无关
+		data := map[string]interface{}{"tx_blob": tx.GetTxJSON("blob")}
+		tx.remote.SubmitContext(ctx, constant.CommandSubmit, data, tx.filter, callback)
+	} else if tx.signsLocally() {
+		tx.sign(ctx, func(err error, blob string) {
 			if nil != err {
 				callback(errors.New("sig error. "+err.Error()), nil)
 			} else {
 				data := map[string]interface{}{"tx_blob": blob}
-				tx.remote.Submit(constant.CommandSubmit, data, tx.filter, callback)
+				tx.remote.SubmitContext(ctx, constant.CommandSubmit, data, tx.filter, callback)
 			}
 		})
-	} else if tx.GetTxJSON("TransactionType") == "Signer" {
-		//直接将blob传给底层
-		data := map[string]interface{}{"tx_blob": tx.GetTxJSON("blob")}
-		tx.remote.Submit(constant.CommandSubmit, data, tx.filter, callback)
+	} else if tx.txData != nil {
+		//强类型交易转成底层的 tx_json 后由底层签名
+		so, err := serializer.FromTx(tx.txData)
+		if err != nil {
+			callback(err, nil)
+			return
+		}
+		txJSON, err := serializer.Decode(so.ToHex())
+		if err != nil {
+			callback(err, nil)
+			return
+		}
+		data := map[string]interface{}{"secret": tx.secret, "tx_json": txJSON}
+		tx.remote.SubmitContext(ctx, constant.CommandSubmit, data, tx.filter, callback)
 	} else {
 		//不签名交易传给底层
 		data := map[string]interface{}{"secret": tx.secret, "tx_json": tx.txJSON}
-		tx.remote.Submit(constant.CommandSubmit, data, tx.filter, callback)
+		tx.remote.SubmitContext(ctx, constant.CommandSubmit, data, tx.filter, callback)
 	}
 }
 
+//signsLocally 提交时是否本地签名：本地签名模式，或只设置了签名者（底层无法签名）
+func (tx *Transaction) signsLocally() bool {
+	return tx.remote.LocalSign || (tx.secret == "" && tx.signer != nil)
+}
+
 func (tx *Transaction) checkTxError() bool {
 	if tx.GetTxJSON(constant.TxJSONErrorKey) != nil {
 		return true
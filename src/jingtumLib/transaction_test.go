@@ -13,10 +13,12 @@ import (
 	"container/list"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"sync"
 	"testing"
 
+	"jingtumlib/constant"
 	"jingtumlib/serializer"
 )
 
@@ -252,6 +254,110 @@ func Test_LocalSignPayment(t *testing.T) {
 	wg.Wait()
 }
 
+//Test_TransactionHash 交易哈希测试，无需连接底层
+func Test_TransactionHash(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	amount := Amount{Currency: "SWT", Value: "0.0001"}
+	tx, err := remote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", amount)
+	if err != nil {
+		t.Fatalf("Build paymanet tx fail : %s", err.Error())
+	}
+
+	if _, err := tx.Hash(); err == nil {
+		t.Fatalf("Hash without secret should fail")
+	}
+
+	tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	tx.AddTxJSON("Sequence", uint32(26))
+	hash, err := tx.Hash()
+	if err != nil {
+		t.Fatalf("Hash fail : %s", err.Error())
+	}
+
+	blob := tx.GetTxJSON("blob").(string)
+	txID, err := serializer.TransactionID(blob)
+	if err != nil || txID != hash || len(hash) != 64 {
+		t.Fatalf("Hash %s, TransactionID %s", hash, txID)
+	}
+
+	//再次签名得到相同的 blob 及哈希
+	signed, err := signing(tx)
+	if err != nil || signed != blob {
+		t.Fatalf("Signing again changed blob")
+	}
+
+	hash2, _ := tx.Hash()
+	if hash2 != hash {
+		t.Fatalf("Hash %s, expect %s", hash2, hash)
+	}
+
+	//底层签名的交易提交前没有哈希，Hash 不签名，也不改变签名方式
+	serverRemote, err := NewRemote("ws://123.57.219.57:5020", false)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+	tx, err = serverRemote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", amount)
+	if err != nil {
+		t.Fatalf("Build paymanet tx fail : %s", err.Error())
+	}
+	tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	tx.AddTxJSON("Sequence", uint32(26))
+	before := fmt.Sprint(tx.txJSON)
+	if _, err := tx.Hash(); err != constant.ERR_TX_LOCAL_SIGN_REQUIRED {
+		t.Fatalf("Hash of server signed tx should fail with ERR_TX_LOCAL_SIGN_REQUIRED, got %v", err)
+	}
+	if after := fmt.Sprint(tx.txJSON); tx.localSign || after != before {
+		t.Fatalf("Hash changed the server signed tx : %s, expect %s", after, before)
+	}
+}
+
+//Test_BuildTx 强类型交易与 txJSON 交易签名结果一致
+func Test_BuildTx(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	amount := Amount{Currency: "SWT", Value: "0.0001"}
+	tx, err := remote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", amount)
+	if err != nil {
+		t.Fatalf("Build paymanet tx fail : %s", err.Error())
+	}
+	tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	tx.AddTxJSON("Sequence", uint32(26))
+	hash, err := tx.Hash()
+	if err != nil {
+		t.Fatalf("Hash fail : %s", err.Error())
+	}
+
+	payment := &serializer.Payment{
+		TxCommon:    serializer.TxCommon{Account: "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", Sequence: 26},
+		Destination: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+		Amount:      constant.Amount{Currency: "SWT", Value: "0.0001"},
+	}
+	typedTx, err := remote.BuildTx(payment)
+	if err != nil {
+		t.Fatalf("BuildTx fail : %s", err.Error())
+	}
+	typedTx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	typedHash, err := typedTx.Hash()
+	if err != nil {
+		t.Fatalf("Hash fail : %s", err.Error())
+	}
+
+	if typedHash != hash || typedTx.GetTransactionType() != "Payment" {
+		t.Fatalf("Typed tx hash %s, expect %s", typedHash, hash)
+	}
+
+	if _, err := remote.BuildTx(&serializer.OfferCancel{}); err == nil {
+		t.Fatalf("BuildTx without account should fail")
+	}
+}
+
 /*
 *以下为remote 性能测试用例
  */
@@ -498,6 +604,168 @@ func BenchmarkLocalSignPayment(b *testing.B) {
 	}
 }
 
+//Test_SigningVectors 本地签名结果与 serializer/testdata/vectors.json 中的标准用例一致，无需连接底层
+func Test_SigningVectors(t *testing.T) {
+	data, err := ioutil.ReadFile("serializer/testdata/vectors.json")
+	if err != nil {
+		t.Fatalf("Read vectors fail : %s", err.Error())
+	}
+
+	var vectors []struct {
+		Name string `json:"name"`
+		Blob string `json:"blob"`
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Unmarshal vectors fail : %s", err.Error())
+	}
+
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	account := "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72"
+	to := "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk"
+	issuer := "jBciDE8Q3uJjf111VeiUNM775AMKHEbBLS"
+	builders := map[string]func() (*Transaction, error){
+		"payment_swt": func() (*Transaction, error) {
+			tx, err := remote.BuildPaymentTx(account, to, Amount{Currency: "SWT", Value: "0.0001"})
+			if err == nil {
+				tx.AddMemo("支付0.0001SWT")
+				tx.AddTxJSON("Sequence", uint32(26))
+			}
+			return tx, err
+		},
+		"payment_iou": func() (*Transaction, error) {
+			tx, err := remote.BuildPaymentTx(account, to, Amount{Currency: "CNY", Issuer: issuer, Value: "0.1"})
+			if err == nil {
+				tx.SetSendMax(constant.Amount{Currency: "CNY", Issuer: issuer, Value: "0.1001"})
+				tx.AddTxJSON("DestinationTag", uint32(12345))
+				tx.AddTxJSON("InvoiceID", "6A8C1F4E0D8A2F5D1E54B8C7E3A1F0B9C2D7E6F5A4B3C2D1E0F9A8B7C6D5E4F3")
+				tx.AddTxJSON("Sequence", uint32(27))
+			}
+			return tx, err
+		},
+		"offer_create_sell": func() (*Transaction, error) {
+			tx, err := remote.BuildOfferCreateTx(map[string]interface{}{"type": "Sell", "source": account,
+				"taker_pays": Amount{Currency: "CNY", Issuer: issuer, Value: "12.5"}, "taker_gets": Amount{Currency: "SWT", Value: "1000"}})
+			if err == nil {
+				tx.AddTxJSON("Expiration", uint32(600000000))
+				tx.AddTxJSON("Sequence", uint32(31))
+			}
+			return tx, err
+		},
+		"offer_cancel": func() (*Transaction, error) {
+			tx, err := remote.BuildOfferCancelTx(map[string]interface{}{"source": account, "sequence": uint32(31)})
+			if err == nil {
+				tx.AddTxJSON("Sequence", uint32(33))
+			}
+			return tx, err
+		},
+		"relation_set_authorize": func() (*Transaction, error) {
+			tx, err := remote.BuildRelationTx(map[string]interface{}{"type": "authorize", "source": account, "target": to,
+				"limit": Amount{Currency: "CNY", Issuer: issuer, Value: "100"}})
+			if err == nil {
+				tx.AddTxJSON("Sequence", uint32(36))
+			}
+			return tx, err
+		},
+		"contract_deploy": func() (*Transaction, error) {
+			tx, err := remote.DeployContractTx(map[string]interface{}{"account": account, "amount": "10",
+				"payload": fmt.Sprintf("%X", "result={}; function Init(t) return result end"), "params": []string{"10", "abc"}})
+			if err == nil {
+				tx.AddTxJSON("Fee", float32(10000000))
+				tx.AddTxJSON("Sequence", uint32(40))
+			}
+			return tx, err
+		},
+	}
+
+	for _, v := range vectors {
+		build, ok := builders[v.Name]
+		if !ok {
+			continue
+		}
+		delete(builders, v.Name)
+
+		tx, err := build()
+		if err != nil {
+			t.Fatalf("%s: build fail : %s", v.Name, err.Error())
+		}
+		tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+
+		blob, err := signing(tx)
+		if err != nil {
+			t.Fatalf("%s: signing fail : %s", v.Name, err.Error())
+		}
+
+		if blob != v.Blob {
+			t.Fatalf("%s: blob %s, expect %s", v.Name, blob, v.Blob)
+		}
+
+		if hash, err := tx.Hash(); err != nil || hash != v.Hash {
+			t.Fatalf("%s: hash %s, expect %s, %v", v.Name, hash, v.Hash, err)
+		}
+	}
+
+	if len(builders) > 0 {
+		t.Fatalf("Vectors not found : %v", builders)
+	}
+}
+
+//BenchmarkSigning 本地签名，无需连接底层
+func BenchmarkSigning(b *testing.B) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		b.Fatalf("New remote fail : %s", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		amount := Amount{Currency: "SWT", Value: "0.0001"}
+		tx, err := remote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", amount)
+		if err != nil {
+			b.Fatalf("Build paymanet tx fail : %s", err.Error())
+		}
+		tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+		tx.AddMemo("支付0.0001SWT")
+		tx.AddTxJSON("Sequence", uint32(26))
+		if _, err := signing(tx); err != nil {
+			b.Fatalf("Signing fail : %s", err.Error())
+		}
+	}
+}
+
+//BenchmarkSigningTx 同 BenchmarkSigning，交易由强类型 Payment 构建
+func BenchmarkSigningTx(b *testing.B) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		b.Fatalf("New remote fail : %s", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		payment := &serializer.Payment{
+			TxCommon: serializer.TxCommon{
+				Account:  "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72",
+				Sequence: 26,
+				Memos:    serializer.Memos{{Memo: &serializer.MemoDataInfo{MemoData: "支付0.0001SWT"}}},
+			},
+			Destination: "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk",
+			Amount:      constant.Amount{Currency: "SWT", Value: "0.0001"},
+		}
+		tx, err := remote.BuildTx(payment)
+		if err != nil {
+			b.Fatalf("Build paymanet tx fail : %s", err.Error())
+		}
+		tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+		if _, err := signing(tx); err != nil {
+			b.Fatalf("Signing fail : %s", err.Error())
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	err := Init()
 	if err != nil {
@@ -0,0 +1,268 @@
+/**
+ * 节点通信的传输层，websocket 支持请求和订阅，HTTP JSON-RPC 只支持请求（如节点的 HTTP 端口，或不能使用 websocket 的环境）。
+ *
+ * @FileName: transport.go
+ */
+package jingtumlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"jingtumlib/constant"
+
+	"github.com/caivega/evtwebsocket"
+)
+
+//Transport 与节点通信的传输层。请求和响应、订阅消息都使用 websocket 接口的 JSON 格式：
+//请求带 id 和 command，响应的 type 为 response，订阅消息的 type 为 transaction、ledgerClosed 等
+type Transport interface {
+	//Dial 连接节点，之后收到的响应和订阅消息交给 onMessage，连接断开时调用 onClose
+	Dial(onMessage func(msg []byte), onClose func(err error)) error
+
+	//Send 发送请求，响应异步交给 onMessage。ctx 为该请求的 ctx（含超时），结束后不必再等待响应；
+	//单个请求失败而连接仍可用时（如 HTTP 请求出错）调用 onFail，该请求不会再有响应
+	Send(ctx context.Context, request []byte, onFail func(err error)) error
+
+	//Close 关闭连接
+	Close() error
+
+	//Subscribable 是否支持订阅消息
+	Subscribable() bool
+}
+
+//TransportFactory 根据节点地址创建传输层，每次连接（含断线重连）都创建新的传输层
+type TransportFactory func(url string) Transport
+
+var (
+	transportLock sync.RWMutex
+	transports    = map[string]TransportFactory{
+		"ws":    newWebsocketTransport,
+		"wss":   newWebsocketTransport,
+		"http":  newHTTPTransport,
+		"https": newHTTPTransport,
+	}
+)
+
+//RegisterTransport 注册地址 scheme 对应的传输层，NewRemote 的地址使用该 scheme 时通过 factory 连接节点
+func RegisterTransport(scheme string, factory TransportFactory) {
+	transportLock.Lock()
+	defer transportLock.Unlock()
+	transports[scheme] = factory
+}
+
+func hasTransport(scheme string) bool {
+	transportLock.RLock()
+	defer transportLock.RUnlock()
+	_, ok := transports[scheme]
+	return ok
+}
+
+//newTransport 根据地址的 scheme 创建传输层
+func newTransport(urlStr string) (Transport, error) {
+	urlParsed, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	transportLock.RLock()
+	factory, ok := transports[urlParsed.Scheme]
+	transportLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unsupported transport %s", urlParsed.Scheme)
+	}
+
+	return factory(urlStr), nil
+}
+
+//websocketTransport websocket 传输层
+type websocketTransport struct {
+	url  string
+	conn *evtwebsocket.Conn
+}
+
+func newWebsocketTransport(url string) Transport {
+	return &websocketTransport{url: url}
+}
+
+func (transport *websocketTransport) Dial(onMessage func(msg []byte), onClose func(err error)) error {
+	transport.conn = &evtwebsocket.Conn{
+		OnMessage: func(msg []byte, w *evtwebsocket.Conn) {
+			// fmt.Printf("On message %s\n", msg)
+			onMessage(msg)
+		},
+
+		MatchMsg: func(req, resp []byte) bool {
+			return true
+		},
+
+		OnError: func(err error) {
+			log.Printf("On error : %s", err.Error())
+			onClose(err)
+		},
+
+		Reconnect: false,
+	}
+
+	return transport.conn.Dial(transport.url, "")
+}
+
+//Send 超时由 Remote 处理，websocket 的请求失败即连接断开，不调用 onFail
+func (transport *websocketTransport) Send(ctx context.Context, request []byte, onFail func(err error)) error {
+	return transport.conn.Send(evtwebsocket.Msg{
+		Body: request,
+		Callback: func(msg []byte, w *evtwebsocket.Conn) {
+			// fmt.Printf("Response message : %s\n", msg)
+		},
+	})
+}
+
+func (transport *websocketTransport) Close() error {
+	return transport.conn.Close()
+}
+
+func (transport *websocketTransport) Subscribable() bool {
+	return true
+}
+
+//httpTimeout HTTP 请求的最长时间，请求的 ctx 没有更早的期限时生效
+const httpTimeout = 30 * time.Second
+
+//httpTransport HTTP JSON-RPC 传输层。请求转成 {"method": command, "params": [参数]} 发送，
+//响应转成 websocket 格式；单个请求出错时该请求失败，连接保持，不支持订阅
+type httpTransport struct {
+	url       string
+	client    *http.Client
+	ctx       context.Context
+	cancel    context.CancelFunc
+	onMessage func(msg []byte)
+	onClose   func(err error)
+}
+
+func newHTTPTransport(url string) Transport {
+	return &httpTransport{url: url, client: &http.Client{Timeout: httpTimeout}}
+}
+
+//Dial 请求 server_info 确认节点可用
+func (transport *httpTransport) Dial(onMessage func(msg []byte), onClose func(err error)) error {
+	transport.ctx, transport.cancel = context.WithCancel(context.Background())
+	transport.onMessage = onMessage
+	transport.onClose = onClose
+	_, err := transport.call(transport.ctx, constant.CommandServerInfo, map[string]interface{}{})
+	return err
+}
+
+//Send 在新的 goroutine 中发送请求，请求随 ctx 或 Close 结束，出错时调用 onFail
+func (transport *httpTransport) Send(ctx context.Context, request []byte, onFail func(err error)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal(request, &params); err != nil {
+		return err
+	}
+
+	id := params["id"]
+	command, _ := params["command"].(string)
+	delete(params, "id")
+	delete(params, "command")
+
+	go func() {
+		result, err := transport.call(ctx, command, params)
+		if err != nil {
+			if transport.ctx.Err() == nil {
+				onFail(err)
+			}
+			return
+		}
+
+		msg, err := json.Marshal(httpResponse(id, result))
+		if err != nil {
+			log.Printf("Marshal response error : %v", err)
+			return
+		}
+		transport.onMessage(msg)
+	}()
+
+	return nil
+}
+
+//call 发送 JSON-RPC 请求，返回响应的 result，请求在 ctx 结束或 Close 时取消。
+//HTTP 状态码或响应格式错误时返回 status 为 error 的 result
+func (transport *httpTransport) call(ctx context.Context, command string, params map[string]interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]interface{}{"method": command, "params": []interface{}{params}})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, transport.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-transport.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := transport.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret struct {
+		Result map[string]interface{} `json:"result"`
+	}
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(data, &ret) != nil || ret.Result == nil {
+		return map[string]interface{}{"status": "error", "error": "httpError", "error_message": fmt.Sprintf("HTTP %d : %s", resp.StatusCode, bytes.TrimSpace(data))}, nil
+	}
+
+	return ret.Result, nil
+}
+
+//httpResponse JSON-RPC 的 result 转成 websocket 格式的响应
+func httpResponse(id interface{}, result map[string]interface{}) map[string]interface{} {
+	status, _ := result["status"].(string)
+	delete(result, "status")
+	if status == "success" {
+		return map[string]interface{}{"id": id, "type": "response", "status": status, "result": result}
+	}
+
+	response := map[string]interface{}{"id": id, "type": "response", "status": "error"}
+	for key, value := range result {
+		response[key] = value
+	}
+	return response
+}
+
+func (transport *httpTransport) Close() error {
+	if transport.cancel != nil {
+		transport.cancel()
+	}
+	return nil
+}
+
+func (transport *httpTransport) Subscribable() bool {
+	return false
+}
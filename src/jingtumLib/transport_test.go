@@ -0,0 +1,232 @@
+/**
+ * 传输层测试类，用本地 HTTP 服务模拟节点的 JSON-RPC 端口
+ *
+ * @FileName: transport_test.go
+ */
+package jingtumlib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jingtumlib/constant"
+)
+
+//mockRPC 模拟节点的 JSON-RPC 端口，account_info 返回账号不存在
+func mockRPC(requests chan map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                   `json:"method"`
+			Params []map[string]interface{} `json:"params"`
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil || len(req.Params) != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		requests <- map[string]interface{}{"method": req.Method, "params": req.Params[0]}
+		result := map[string]interface{}{"status": "success"}
+		switch req.Method {
+		case constant.CommandServerInfo:
+			result["info"] = map[string]interface{}{"server_state": "full", "complete_ledgers": "1-100", "validated_ledger": map[string]interface{}{"seq": 100}}
+		case constant.CommandAccountInfo:
+			result = map[string]interface{}{"status": "error", "error": "actNotFound", "error_message": "Account not found.", "request": req.Params[0]}
+		case constant.CommandLedger:
+			result["ledger"] = map[string]interface{}{"ledger_index": "100", "hash": "AB"}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	}))
+}
+
+//Test_HTTPTransport 通过 HTTP JSON-RPC 请求，订阅返回错误
+func Test_HTTPTransport(t *testing.T) {
+	requests := make(chan map[string]interface{}, 100)
+	srv := mockRPC(requests)
+	defer srv.Close()
+
+	remote, err := NewRemote(srv.URL, true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err.Error())
+	}
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	defer remote.Disconnect()
+
+	if req := <-requests; req["method"] != constant.CommandServerInfo {
+		t.Errorf("Dial should request server_info, got %v", req)
+	}
+
+	result := make(chan interface{}, 1)
+	errs := make(chan error, 1)
+	req, _ := remote.RequestLedger(map[string]interface{}{"ledger_index": "100"})
+	req.Submit(func(err error, data interface{}) {
+		errs <- err
+		result <- data
+	})
+	if err := <-errs; err != nil {
+		t.Fatalf("Request ledger fail : %s", err.Error())
+	}
+	if ledger := (<-result).(map[string]interface{}); ledger["ledger_hash"] != "AB" {
+		t.Errorf("Ledger hash should be AB, got %v", ledger)
+	}
+
+	params := (<-requests)["params"].(map[string]interface{})
+	if _, ok := params["id"]; ok || params["ledger_index"] != float64(100) {
+		t.Errorf("JSON-RPC params should not have id and should have ledger_index, got %v", params)
+	}
+
+	req, _ = remote.RequestAccountInfo(map[string]interface{}{"account": "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72"})
+	req.Submit(func(err error, data interface{}) {
+		errs <- err
+	})
+	if err := <-errs; err == nil || err.Error() != "Account not found." {
+		t.Errorf("Request account info should fail with Account not found., got %v", err)
+	}
+
+	remote.Subscribe([]string{"peer_status"}).Submit(func(err error, data interface{}) {
+		errs <- err
+	})
+	if err := <-errs; err != constant.ERR_SERVER_NOT_SUBSCRIBABLE {
+		t.Errorf("Subscribe should fail with ERR_SERVER_NOT_SUBSCRIBABLE, got %v", err)
+	}
+}
+
+//Test_HTTPRequestFailure 单个 HTTP 请求出错只结束该请求，不断开连接；请求的 ctx 结束时取消 HTTP 请求
+func Test_HTTPRequestFailure(t *testing.T) {
+	canceled := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case constant.CommandServerInfo:
+			json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"status": "success", "info": map[string]interface{}{"server_state": "full"}}})
+		case constant.CommandLedger:
+			//断开连接，HTTP 请求出错
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		default:
+			<-r.Context().Done()
+			canceled <- struct{}{}
+		}
+	}))
+	defer srv.Close()
+
+	remote, err := NewRemote(srv.URL, true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err.Error())
+	}
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	defer remote.Disconnect()
+
+	errs := make(chan error, 1)
+	req, _ := remote.RequestLedger(map[string]interface{}{})
+	req.Submit(func(err error, data interface{}) {
+		errs <- err
+	})
+	if err := <-errs; err == nil {
+		t.Fatalf("Request ledger should fail")
+	}
+	if !remote.IsConnected() {
+		t.Fatalf("HTTP request error should not disconnect the server")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ = remote.RequestAccountInfo(map[string]interface{}{"account": "jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72"})
+	req.SubmitContext(ctx, func(err error, data interface{}) {
+		errs <- err
+	})
+	if _, ok := (<-errs).(*TimeoutError); !ok {
+		t.Fatalf("Request should time out")
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("HTTP request is not canceled with the request ctx")
+	}
+	if !remote.IsConnected() {
+		t.Fatalf("Request timeout should not disconnect the server")
+	}
+}
+
+//Test_TransportPool HTTP 节点和 websocket 节点组成节点池，订阅只发送到 websocket 节点，HTTP 节点不可达时请求转移到其他节点
+func Test_TransportPool(t *testing.T) {
+	requests := make(chan map[string]interface{}, 100)
+	rpc := mockRPC(requests)
+	node, srv := newMockNode()
+	defer srv.Close()
+
+	remote, err := NewRemotePool([]string{rpc.URL, strings.Replace(srv.URL, "http://", "ws://", 1)}, true)
+	if err != nil {
+		t.Fatalf("New remote pool fail : %s", err.Error())
+	}
+	remote.SetReconnectPolicy(&ReconnectPolicy{MinDelay: time.Second, MaxDelay: time.Second})
+	if err := remote.Connect(func(err error, result interface{}) {}); err != nil {
+		t.Fatalf("Connect service fail : %s", err.Error())
+	}
+	defer remote.Disconnect()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !remote.servers[0].IsConnected() || !remote.servers[1].IsConnected() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Wait servers online timeout : %v", remote.ServerHealth())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	node.waitRequest(t, constant.CommandSubscribe)
+
+	errs := make(chan error, 1)
+	remote.Subscribe([]string{"peer_status"}).Submit(func(err error, data interface{}) {
+		errs <- err
+	})
+	if err := <-errs; err != nil {
+		t.Errorf("Subscribe should success on websocket server, got %v", err)
+	}
+	node.waitRequest(t, constant.CommandSubscribe)
+
+	//HTTP 节点响应最快时请求发送到 HTTP 节点，节点不可达后转移到 websocket 节点
+	for i, latency := range []time.Duration{time.Nanosecond, time.Second} {
+		remote.servers[i].l.Lock()
+		remote.servers[i].latency = latency
+		remote.servers[i].l.Unlock()
+	}
+	rpc.Close()
+
+	req, _ := remote.RequestLedger(map[string]interface{}{})
+	req.Submit(func(err error, data interface{}) {
+		errs <- err
+	})
+	node.waitRequest(t, constant.CommandLedger)
+	if err := <-errs; err != nil {
+		t.Errorf("Request should fail over to websocket server, got %v", err)
+	}
+
+	for _, req := range drain(requests) {
+		if req["method"] == constant.CommandSubscribe {
+			t.Errorf("HTTP server should not receive subscribe")
+		}
+	}
+}
+
+func drain(requests chan map[string]interface{}) []map[string]interface{} {
+	var ret []map[string]interface{}
+	for {
+		select {
+		case req := <-requests:
+			ret = append(ret, req)
+		default:
+			return ret
+		}
+	}
+}
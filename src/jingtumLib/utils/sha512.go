@@ -41,12 +41,8 @@ func (s *Sha512) Add(bytes []byte) (int, error) {
 
 //Add32 Add32
 func (s *Sha512) Add32(i uint32) (int, error) {
-	var b []byte
-	b = append(b, byte(((i >> 24) & 0xFF)))
-	b = append(b, byte(((i >> 16) & 0xFF)))
-	b = append(b, byte(((i >> 8) & 0xFF)))
-	b = append(b, byte((i & 0xFF)))
-	return s.h.Write(b)
+	b := [4]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)}
+	return s.h.Write(b[:])
 }
 
 //Finish256 32字节
@@ -22,6 +22,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	jtConst "jingtumlib/constant"
 	jtEncode "jingtumlib/encoding"
@@ -57,7 +58,7 @@ func EncodeB58(version uint8, bytes []byte) string {
 //DecodeB58 DecodeB58
 func DecodeB58(version uint8, input string) (decodedBytes []byte, err error) {
 	decodedBytes, err = jtEncode.Base58Decode(input, jtEncode.JingTumAlphabet)
-	if err != nil || decodedBytes[0] != version || len(decodedBytes) < 5 {
+	if err != nil || len(decodedBytes) < 5 || decodedBytes[0] != version {
 		err = errors.New("invalid input size")
 		return
 	}
@@ -77,6 +78,28 @@ func DecodeB58(version uint8, input string) (decodedBytes []byte, err error) {
 	return
 }
 
+//EncodeB58Prefix 多字节版本前缀的 base58check 编码，如 ed25519 私钥
+func EncodeB58Prefix(prefix []byte, bytes []byte) string {
+	buffer := bufCat1(prefix, bytes)
+	checksum := Sha256Util(Sha256Util(buffer))[0:4]
+	return jtEncode.Base58Encode(bufCat1(buffer, checksum), jtEncode.JingTumAlphabet)
+}
+
+//DecodeB58Prefix 多字节版本前缀的 base58check 解码，返回去掉前缀和校验码的数据
+func DecodeB58Prefix(prefix []byte, input string) ([]byte, error) {
+	decodedBytes, err := jtEncode.Base58Decode(input, jtEncode.JingTumAlphabet)
+	if err != nil || len(decodedBytes) < len(prefix)+4 || !bytes.Equal(decodedBytes[:len(prefix)], prefix) {
+		return nil, errors.New("invalid input size")
+	}
+
+	computed := Sha256Util(Sha256Util(decodedBytes[0 : len(decodedBytes)-4]))[0:4]
+	if !bytes.Equal(computed, decodedBytes[len(decodedBytes)-4:]) {
+		return nil, errors.New("invalid checksum")
+	}
+
+	return decodedBytes[len(prefix) : len(decodedBytes)-4], nil
+}
+
 //BytesToBigInt BytesToBigInt
 func BytesToBigInt(b []byte) *big.Int {
 	bBuf := bytes.NewBuffer(b)
@@ -87,8 +110,7 @@ func BytesToBigInt(b []byte) *big.Int {
 
 //IsValidCurrency 货币合法性验证
 func IsValidCurrency(currency string) bool {
-	ok, _ := regexp.MatchString(jtConst.RegexCurrency, currency)
-	return ok
+	return MatchString(jtConst.RegexCurrency, currency)
 }
 
 //DecodeAddress 地址解码。
@@ -144,11 +166,22 @@ func GetBytes(value interface{}) []byte {
 	return bytesBuffer.Bytes()
 }
 
-//MatchString MatchString
+//regexpCache 已编译的正则表达式，避免每次匹配都重新编译
+var regexpCache sync.Map
+
+//MatchString 正则匹配，编译结果按表达式缓存
 func MatchString(patter string, str string) bool {
-	match, _ := regexp.MatchString(patter, str)
+	if re, ok := regexpCache.Load(patter); ok {
+		return re.(*regexp.Regexp).MatchString(str)
+	}
 
-	return match
+	re, err := regexp.Compile(patter)
+	if err != nil {
+		return false
+	}
+	regexpCache.Store(patter, re)
+
+	return re.MatchString(str)
 }
 
 //IsNumberType IsNumberType
@@ -204,8 +237,18 @@ func NumberToString(obj interface{}) string {
 
 //IsHexString 16进制格式验证
 func IsHexString(str string) bool {
-	match, _ := regexp.MatchString("^[0-9a-fA-F]+$", str)
-	return match
+	if str == "" {
+		return false
+	}
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+
+	return true
 }
 
 //ToAmount 根据货币类型转换成相应的金额对象。如果是SWT则返回基本数据类型
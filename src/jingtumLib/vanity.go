@@ -0,0 +1,212 @@
+/**
+ * 靓号地址生成，多个协程随机生成钱包，直到地址符合指定的前缀、后缀或正则表达式。
+ *
+ * @FileName: vanity.go
+ */
+package jingtumlib
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"jingtumlib/crypto"
+	"jingtumlib/encoding"
+)
+
+//VanityOptions 靓号地址的匹配条件，Prefix、Suffix 和 Pattern 至少设置一个，同时设置时须全部满足
+type VanityOptions struct {
+	//Prefix 地址前缀，井通地址都以 j 开头，前缀须包含开头的 j
+	Prefix string
+	//Suffix 地址后缀
+	Suffix string
+	//Pattern 地址须匹配的正则表达式
+	Pattern string
+	//KeyType 签名算法，默认 crypto.Secp256k1
+	KeyType crypto.KeyType
+	//Workers 协程数，默认为 CPU 数
+	Workers int
+}
+
+//VanitySearch 靓号地址搜索，由 NewVanitySearch 创建，Run 执行搜索，搜索中可以调用 Attempts 和 Rate 查看进度
+type VanitySearch struct {
+	attempts uint64 //原子操作，放在第一个字段以保证 32 位平台上 8 字节对齐
+	options  VanityOptions
+	keyPair  crypto.KeyPair
+	pattern  *regexp.Regexp
+	started  atomic.Value
+}
+
+//NewVanitySearch 校验匹配条件并创建搜索。前缀、后缀和正则表达式中的字符须属于井通 base58 字母表，
+//否则不可能匹配（如 0、O、I、l）
+func NewVanitySearch(options VanityOptions) (*VanitySearch, error) {
+	if options.Prefix == "" && options.Suffix == "" && options.Pattern == "" {
+		return nil, fmt.Errorf("Prefix, suffix or pattern is required")
+	}
+
+	if options.Prefix != "" && !strings.HasPrefix(options.Prefix, "j") {
+		return nil, fmt.Errorf("Prefix %s must start with j", options.Prefix)
+	}
+
+	for _, s := range []string{options.Prefix, options.Suffix} {
+		for _, ch := range s {
+			if !encoding.JingTumAlphabet.Contains(ch) {
+				return nil, fmt.Errorf("Invalid character %q in %s, not in base58 alphabet", ch, s)
+			}
+		}
+	}
+
+	if options.KeyType == "" {
+		options.KeyType = crypto.Secp256k1
+	}
+	keyPair, ok := keyPairs[options.KeyType]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported key type %s", options.KeyType)
+	}
+
+	if options.Workers <= 0 {
+		options.Workers = runtime.NumCPU()
+	}
+
+	search := &VanitySearch{options: options, keyPair: keyPair}
+	if options.Pattern != "" {
+		re, err := syntax.Parse(options.Pattern, syntax.Perl)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkVanityPattern(re); err != nil {
+			return nil, err
+		}
+
+		search.pattern = regexp.MustCompile(options.Pattern)
+	}
+
+	return search, nil
+}
+
+//checkVanityPattern 正则表达式中的字面字符须属于 base58 字母表，忽略大小写时任一大小写属于即可
+func checkVanityPattern(re *syntax.Regexp) error {
+	if re.Op == syntax.OpLiteral {
+		for _, ch := range re.Rune {
+			if encoding.JingTumAlphabet.Contains(ch) {
+				continue
+			}
+			if re.Flags&syntax.FoldCase != 0 && (encoding.JingTumAlphabet.Contains(unicode.ToUpper(ch)) || encoding.JingTumAlphabet.Contains(unicode.ToLower(ch))) {
+				continue
+			}
+			return fmt.Errorf("Invalid character %q in pattern, not in base58 alphabet", ch)
+		}
+	}
+
+	for _, sub := range re.Sub {
+		if err := checkVanityPattern(sub); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Match 地址是否符合匹配条件
+func (search *VanitySearch) Match(address string) bool {
+	if !strings.HasPrefix(address, search.options.Prefix) || !strings.HasSuffix(address, search.options.Suffix) {
+		return false
+	}
+
+	return search.pattern == nil || search.pattern.MatchString(address)
+}
+
+//Run 启动 Workers 个协程搜索，返回第一个符合条件的钱包。parent 取消或超时时停止搜索，返回 parent.Err()
+func (search *VanitySearch) Run(parent context.Context) (*Wallet, error) {
+	search.started.Store(time.Now())
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	found := make(chan *Wallet, 1)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < search.options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				wallet, err := search.generate()
+				if err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+					cancel()
+					return
+				}
+
+				atomic.AddUint64(&search.attempts, 1)
+				if search.Match(wallet.GetAddress()) {
+					select {
+					case found <- wallet:
+					default:
+					}
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case wallet := <-found:
+		return wallet, nil
+	default:
+	}
+
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+		return nil, parent.Err()
+	}
+}
+
+func (search *VanitySearch) generate() (*Wallet, error) {
+	secret, err := search.keyPair.GenerateSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := search.keyPair.DeriveKeyPair(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Wallet{priv: priv, secret: secret}, nil
+}
+
+//Attempts 已生成的地址数
+func (search *VanitySearch) Attempts() uint64 {
+	return atomic.LoadUint64(&search.attempts)
+}
+
+//Rate 每秒生成的地址数
+func (search *VanitySearch) Rate() float64 {
+	started, ok := search.started.Load().(time.Time)
+	if !ok {
+		return 0
+	}
+
+	elapsed := time.Since(started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(search.Attempts()) / elapsed
+}
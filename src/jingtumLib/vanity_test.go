@@ -0,0 +1,96 @@
+/**
+ * 靓号地址生成测试类
+ *
+ * @FileName: vanity_test.go
+ */
+package jingtumlib
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"jingtumlib/crypto"
+)
+
+//Test_Vanity 搜索后缀和正则表达式匹配的地址，ed25519 密钥生成较快
+func Test_Vanity(t *testing.T) {
+	search, err := NewVanitySearch(VanityOptions{Suffix: "j", Pattern: "(?i)[a-k]{2}", KeyType: crypto.Ed25519, Workers: 4})
+	if err != nil {
+		t.Fatalf("NewVanitySearch fail : %s", err.Error())
+	}
+
+	wallet, err := search.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run fail : %s", err.Error())
+	}
+
+	if !strings.HasSuffix(wallet.GetAddress(), "j") || !search.Match(wallet.GetAddress()) {
+		t.Fatalf("Address %s does not match", wallet.GetAddress())
+	}
+
+	if wallet.GetKeyType() != crypto.Ed25519 || search.Attempts() == 0 || search.Rate() <= 0 {
+		t.Fatalf("Key type %s, attempts %d, rate %f", wallet.GetKeyType(), search.Attempts(), search.Rate())
+	}
+
+	//私钥可以恢复同一钱包
+	restored, err := FromSecret(wallet.GetSecret())
+	if err != nil || restored.GetAddress() != wallet.GetAddress() {
+		t.Fatalf("FromSecret %s, err %v", restored.GetAddress(), err)
+	}
+}
+
+//Test_VanityCancel 取消或超时时停止搜索
+func Test_VanityCancel(t *testing.T) {
+	search, err := NewVanitySearch(VanityOptions{Prefix: "jjjjjjjjjj", KeyType: crypto.Ed25519, Workers: 2})
+	if err != nil {
+		t.Fatalf("NewVanitySearch fail : %s", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if _, err := search.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Run err %v", err)
+	}
+
+	if search.Attempts() == 0 {
+		t.Fatalf("No attempts")
+	}
+}
+
+//Test_VanityOptions 匹配条件须属于 base58 字母表
+func Test_VanityOptions(t *testing.T) {
+	invalid := []VanityOptions{
+		{},
+		{Prefix: "abc"},
+		{Prefix: "j0"},
+		{Suffix: "Il"},
+		{Pattern: "^jO"},
+		{Pattern: "["},
+		{Suffix: "a", KeyType: "rsa"},
+	}
+	for _, options := range invalid {
+		if _, err := NewVanitySearch(options); err == nil {
+			t.Fatalf("%+v should fail", options)
+		}
+	}
+
+	valid := []VanityOptions{
+		{Prefix: "jGate"},
+		{Suffix: "Hot"},
+		{Pattern: "(?i)cold$"},
+		{Pattern: "[0-9]{4}"},
+	}
+	for _, options := range valid {
+		if _, err := NewVanitySearch(options); err != nil {
+			t.Fatalf("%+v fail : %s", options, err.Error())
+		}
+	}
+
+	search, _ := NewVanitySearch(VanityOptions{Prefix: "jGX", Pattern: "Zj72$"})
+	if !search.Match("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72") || search.Match("j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk") {
+		t.Fatalf("Match fail")
+	}
+}
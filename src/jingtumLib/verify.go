@@ -0,0 +1,197 @@
+/**
+ * 交易签名验证。
+ *
+ * @FileName: verify.go
+ */
+package jingtumlib
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/crypto/ed25519"
+	"jingtumlib/crypto/secp256k1"
+	"jingtumlib/serializer"
+)
+
+//VerifyTransaction 验证已签名交易 blob 的签名，并确认签名公钥对应交易的 Account 或 regularKeys 中的关联密钥地址。
+//验证通过时返回解码后的交易 JSON。
+func VerifyTransaction(blob string, regularKeys ...string) (map[string]interface{}, error) {
+	txJSON, err := serializer.Decode(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := VerifyTxJSON(txJSON, regularKeys...); err != nil {
+		return nil, err
+	}
+
+	return txJSON, nil
+}
+
+//VerifyTxJSON 验证 tx_json 格式（SWT 金额为 drops）的已签名交易，如 transactions 订阅推送的 transaction，小写开头的字段（hash、date 等）不参与验证
+//多重签名的交易验证每个签名者的签名，签名公钥须对应签名者账号或 regularKeys 中的地址
+func VerifyTxJSON(txJSON map[string]interface{}, regularKeys ...string) error {
+	if isMultiSigned(txJSON) {
+		return verifySigners(txJSON, regularKeys)
+	}
+
+	signer, err := verifySignature(txJSON)
+	if err != nil {
+		return err
+	}
+
+	if account, _ := txJSON["Account"].(string); signer == account {
+		return nil
+	}
+
+	if containsString(regularKeys, signer) {
+		return nil
+	}
+
+	return constant.ERR_TX_SIGNER_NOT_AUTHORIZED
+}
+
+//VerifyTransaction 验证已签名交易 blob，签名公钥不是 Account 的公钥时，查询 Account 当前的关联密钥（RegularKey）再验证。
+//验证通过时 result 为解码后的交易 JSON。
+func (remote *Remote) VerifyTransaction(blob string, callback func(err error, result interface{})) {
+	txJSON, err := serializer.Decode(blob)
+	if err != nil {
+		callback(err, nil)
+		return
+	}
+
+	remote.VerifyTxJSON(txJSON, callback)
+}
+
+//VerifyTxJSON 验证 tx_json 格式的已签名交易，签名公钥不是 Account 的公钥时，查询 Account 当前的关联密钥（RegularKey）再验证。
+//多重签名的交易不查询关联密钥，与 VerifyTxJSON(txJSON) 相同
+func (remote *Remote) VerifyTxJSON(txJSON map[string]interface{}, callback func(err error, result interface{})) {
+	if isMultiSigned(txJSON) {
+		if err := verifySigners(txJSON, nil); err != nil {
+			callback(err, nil)
+			return
+		}
+
+		callback(nil, txJSON)
+		return
+	}
+
+	signer, err := verifySignature(txJSON)
+	if err != nil {
+		callback(err, nil)
+		return
+	}
+
+	account, _ := txJSON["Account"].(string)
+	if signer == account {
+		callback(nil, txJSON)
+		return
+	}
+
+	req, err := remote.RequestAccountInfo(map[string]interface{}{"account": account})
+	if err != nil {
+		callback(err, nil)
+		return
+	}
+
+	req.Submit(func(err error, result interface{}) {
+		if err != nil {
+			callback(err, nil)
+			return
+		}
+
+		ret, ok := result.(map[string]interface{})
+		if !ok {
+			callback(fmt.Errorf("Request account info fail"), nil)
+			return
+		}
+
+		actData, ok := ret["account_data"].(map[string]interface{})
+		if !ok {
+			callback(fmt.Errorf("account_data type %T error", ret["account_data"]), nil)
+			return
+		}
+
+		if regularKey, _ := actData["RegularKey"].(string); regularKey == "" || regularKey != signer {
+			callback(constant.ERR_TX_SIGNER_NOT_AUTHORIZED, nil)
+			return
+		}
+
+		callback(nil, txJSON)
+	})
+}
+
+//verifySignature 按 STX 前缀重新计算签名数据并验证 TxnSignature，返回签名公钥对应的地址
+func verifySignature(txJSON map[string]interface{}) (string, error) {
+	pubHex, _ := txJSON["SigningPubKey"].(string)
+	sigHex, _ := txJSON["TxnSignature"].(string)
+	if pubHex == "" || sigHex == "" {
+		return "", constant.ERR_TX_NOT_SIGNED
+	}
+
+	pubKey, err := hex.DecodeString(pubHex)
+	if err != nil || len(pubKey) != 33 {
+		return "", fmt.Errorf("Invalid SigningPubKey %s", pubHex)
+	}
+
+	signature, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return "", fmt.Errorf("Invalid TxnSignature %s", sigHex)
+	}
+
+	//签名数据不含 TxnSignature
+	unsigned := make(map[string]interface{}, len(txJSON))
+	for k, v := range txJSON {
+		if k != "TxnSignature" {
+			unsigned[k] = v
+		}
+	}
+
+	so, err := serializer.FromTxJSON(unsigned)
+	if err != nil {
+		return "", err
+	}
+	message := so.SigningData(constant.HashPrefixTxSign)
+	so.Release()
+
+	if !verifyKeySignature(pubKey, message, signature, isFullyCanonical(txJSON)) {
+		return "", constant.ERR_TX_INVALID_SIGNATURE
+	}
+
+	return crypto.AddressFromPublicKey(pubKey), nil
+}
+
+//verifyKeySignature 按公钥前缀选择 ed25519 或 secp256k1 验证签名，secp256k1 签名须为规范签名，
+//fullyCanonical 时还要求 low-S。ed25519 签名总是规范的
+func verifyKeySignature(pubKey []byte, message []byte, signature []byte, fullyCanonical bool) bool {
+	if pubKey[0] == constant.Ed25519PubKeyPrefix {
+		return ed25519.Verify(pubKey, message, signature)
+	}
+
+	return secp256k1.Verify(pubKey, message, signature, fullyCanonical)
+}
+
+//isFullyCanonical 交易是否设置了 FullyCanonicalSig 标志
+func isFullyCanonical(txJSON map[string]interface{}) bool {
+	var flags uint32
+	switch v := txJSON["Flags"].(type) {
+	case uint32:
+		flags = v
+	case int:
+		flags = uint32(v)
+	case float64:
+		flags = uint32(v)
+	}
+
+	return flags&constant.TxFlagFullyCanonicalSig != 0
+}
+
+//isMultiSigned 多重签名的交易 SigningPubKey 为空，签名在 TxnSignatures 中
+func isMultiSigned(txJSON map[string]interface{}) bool {
+	pubKey, _ := txJSON["SigningPubKey"].(string)
+	_, ok := txJSON["TxnSignatures"]
+	return pubKey == "" && ok
+}
@@ -0,0 +1,174 @@
+/**
+ * 交易签名验证测试类
+ *
+ * @FileName: verify_test.go
+ */
+package jingtumlib
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+
+	"jingtumlib/constant"
+	"jingtumlib/serializer"
+)
+
+//Test_VerifyTransaction 验证向量中已签名的交易，无需连接底层
+func Test_VerifyTransaction(t *testing.T) {
+	data, err := ioutil.ReadFile("serializer/testdata/vectors.json")
+	if err != nil {
+		t.Fatalf("Read vectors fail : %s", err.Error())
+	}
+
+	var vectors []struct {
+		Name   string                 `json:"name"`
+		TxJSON map[string]interface{} `json:"tx_json"`
+		Blob   string                 `json:"blob"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Unmarshal vectors fail : %s", err.Error())
+	}
+
+	count := 0
+	for _, v := range vectors {
+		_, signed := v.TxJSON["TxnSignature"]
+		if _, ok := v.TxJSON["TxnSignatures"]; !signed && !ok {
+			if _, err := VerifyTransaction(v.Blob); err != constant.ERR_TX_NOT_SIGNED {
+				t.Fatalf("%s: unsigned blob err %v", v.Name, err)
+			}
+			continue
+		}
+		count++
+
+		txJSON, err := VerifyTransaction(v.Blob)
+		if err != nil {
+			t.Fatalf("%s: VerifyTransaction fail : %s", v.Name, err.Error())
+		}
+
+		if txJSON["Account"] != v.TxJSON["Account"] {
+			t.Fatalf("%s: Account %v", v.Name, txJSON["Account"])
+		}
+
+		//篡改 Sequence
+		txJSON["Sequence"] = txJSON["Sequence"].(uint32) + 1
+		if err := VerifyTxJSON(txJSON); err != constant.ERR_TX_INVALID_SIGNATURE {
+			t.Fatalf("%s: tampered tx err %v", v.Name, err)
+		}
+	}
+
+	if count == 0 {
+		t.Fatalf("No signed vectors")
+	}
+}
+
+//Test_VerifyCanonical 设置了 FullyCanonicalSig 标志的交易不接受 high-S 签名
+func Test_VerifyCanonical(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	tx, err := remote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", Amount{Currency: "SWT", Value: "1"})
+	if err != nil {
+		t.Fatalf("Build payment tx fail : %s", err.Error())
+	}
+	tx.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	tx.SetFlags([]string{"PartialPayment"})
+	tx.AddTxJSON("Sequence", uint32(50))
+
+	blob, err := signing(tx)
+	if err != nil {
+		t.Fatalf("Signing fail : %s", err.Error())
+	}
+
+	txJSON, err := VerifyTransaction(blob)
+	if err != nil {
+		t.Fatalf("VerifyTransaction fail : %s", err.Error())
+	}
+
+	//本地签名自动设置 FullyCanonicalSig，原有标志不变
+	if txJSON["Flags"] != constant.TxFlagFullyCanonicalSig|TransactionFlags["Payment"]["PartialPayment"] {
+		t.Fatalf("Flags %v", txJSON["Flags"])
+	}
+
+	//把 S 换成 N - S
+	signature, _ := hex.DecodeString(txJSON["TxnSignature"].(string))
+	rLen := int(signature[3])
+	n, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	high := new(big.Int).Sub(n, new(big.Int).SetBytes(signature[6+rLen:])).Bytes()
+	if high[0]&0x80 != 0 {
+		high = append([]byte{0}, high...)
+	}
+	malleated := append([]byte{0x30, byte(4 + rLen + len(high))}, signature[2:4+rLen]...)
+	malleated = append(append(malleated, 0x02, byte(len(high))), high...)
+
+	txJSON["TxnSignature"] = fmt.Sprintf("%X", malleated)
+	if err := VerifyTxJSON(txJSON); err != constant.ERR_TX_INVALID_SIGNATURE {
+		t.Fatalf("High S signature err %v", err)
+	}
+
+	//相同交易签名结果相同
+	tx2, _ := remote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", Amount{Currency: "SWT", Value: "1"})
+	tx2.SetSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3")
+	tx2.SetFlags([]string{"PartialPayment"})
+	tx2.AddTxJSON("Sequence", uint32(50))
+	if blob2, _ := signing(tx2); blob2 != blob {
+		t.Fatalf("Signing is not deterministic : %s, %s", blob, blob2)
+	}
+}
+
+//Test_VerifyRegularKey 由其他密钥签名的交易，只有在给出对应的关联密钥时才通过验证
+func Test_VerifyRegularKey(t *testing.T) {
+	remote, err := NewRemote("ws://123.57.219.57:5020", true)
+	if err != nil {
+		t.Fatalf("New remote fail : %s", err)
+	}
+
+	regular, err := FromSecret("sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j")
+	if err != nil {
+		t.Fatalf("FromSecret fail : %s", err.Error())
+	}
+
+	tx, err := remote.BuildPaymentTx("jGXjV57AKG7dpEv8T6x5H6nmPvNK5tZj72", "j3N35VHut94dD1Y9H1KoWmGZE2kNNRFcVk", Amount{Currency: "SWT", Value: "1"})
+	if err != nil {
+		t.Fatalf("Build payment tx fail : %s", err.Error())
+	}
+	tx.SetSecret(regular.GetSecret())
+	tx.AddTxJSON("Sequence", uint32(50))
+
+	blob, err := signing(tx)
+	if err != nil {
+		t.Fatalf("Signing fail : %s", err.Error())
+	}
+
+	if _, err := VerifyTransaction(blob); err != constant.ERR_TX_SIGNER_NOT_AUTHORIZED {
+		t.Fatalf("Verify without regular key err %v", err)
+	}
+
+	txJSON, err := VerifyTransaction(blob, regular.GetAddress())
+	if err != nil {
+		t.Fatalf("Verify with regular key fail : %s", err.Error())
+	}
+
+	if !strings.HasPrefix(txJSON["SigningPubKey"].(string), "ED") {
+		t.Fatalf("SigningPubKey %v", txJSON["SigningPubKey"])
+	}
+
+	//tx_json 中的小写字段不参与验证
+	txJSON["hash"], _ = serializer.TransactionID(blob)
+	txJSON["date"] = float64(600000000)
+	if err := VerifyTxJSON(txJSON, regular.GetAddress()); err != nil {
+		t.Fatalf("VerifyTxJSON fail : %s", err.Error())
+	}
+
+	//篡改签名
+	txJSON["TxnSignature"] = strings.Repeat("00", 64)
+	if err := VerifyTxJSON(txJSON, regular.GetAddress()); err != constant.ERR_TX_INVALID_SIGNATURE {
+		t.Fatalf("Invalid signature err %v", err)
+	}
+}
@@ -7,19 +7,29 @@
 package jingtumlib
 
 import (
-	"crypto/ecdsa"
+	"crypto/sha256"
 	"fmt"
+	"sync"
+	"time"
 
 	"jingtumlib/constant"
+	"jingtumlib/crypto"
+	"jingtumlib/crypto/ed25519"
+	"jingtumlib/crypto/mnemonic"
 	"jingtumlib/crypto/secp256k1"
+	jtLRU "jingtumlib/lruCache"
 	"jingtumlib/utils"
-
-	"github.com/btcsuite/btcd/btcec"
 )
 
+//keyPairs 支持的签名算法
+var keyPairs = map[crypto.KeyType]crypto.KeyPair{
+	crypto.Secp256k1: &secp256k1.Secp256KeyPair{},
+	crypto.Ed25519:   &ed25519.Ed25519KeyPair{},
+}
+
 //Wallet 钱包结构体
 type Wallet struct {
-	priv   *secp256k1.PrivateKey
+	priv   crypto.PrivateKey
 	secret string
 }
 
@@ -32,14 +42,88 @@ func IsValidAddress(address string) bool {
 	return utils.IsValidAddress(address)
 }
 
-//IsValidSecret 钱包私钥合法性验证
+//DefaultKeyCacheSize 私钥派生的密钥缓存默认大小，可由配置 [Config] key_cache_size 或 SetKeyCacheSize 修改
+const DefaultKeyCacheSize = 1000
+
+const keyCacheTimeout = 30 * time.Minute
+
+//keyCache 私钥派生的密钥缓存，以私钥的 sha256 哈希为键，批量签名时同一私钥只派生一次。为 nil 时不缓存
+var (
+	keyCache, _  = jtLRU.NewLRU(DefaultKeyCacheSize, keyCacheTimeout, nil)
+	keyCacheLock sync.RWMutex
+)
+
+//SetKeyCacheSize 设置私钥派生的密钥缓存大小，0 关闭缓存并清空已缓存的密钥
+func SetKeyCacheSize(size int) error {
+	if size < 0 {
+		return fmt.Errorf("Invalid key cache size %d", size)
+	}
+
+	var cache *jtLRU.LRU
+	if size > 0 {
+		var err error
+		cache, err = jtLRU.NewLRU(size, keyCacheTimeout, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	keyCacheLock.Lock()
+	defer keyCacheLock.Unlock()
+	if keyCache != nil {
+		keyCache.Clear()
+	}
+	keyCache = cache
+	return nil
+}
+
+//getKeyCache 当前的密钥缓存，关闭时为 nil
+func getKeyCache() *jtLRU.LRU {
+	keyCacheLock.RLock()
+	defer keyCacheLock.RUnlock()
+	return keyCache
+}
+
+//deriveKey 根据私钥前缀选择签名算法派生密钥
+func deriveKey(secret string) (crypto.PrivateKey, error) {
+	return keyPairs[crypto.SecretKeyType(secret)].DeriveKeyPair(secret)
+}
+
+//cachedKey 派生密钥，结果缓存在 keyCache 中，仅用于 FromSecret
+func cachedKey(secret string) (crypto.PrivateKey, error) {
+	cache := getKeyCache()
+	if cache == nil {
+		return deriveKey(secret)
+	}
+
+	hash := sha256.Sum256([]byte(secret))
+	if priv, ok := cache.Get(hash); ok {
+		return priv.(crypto.PrivateKey), nil
+	}
+
+	priv, err := deriveKey(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Add(hash, priv)
+	return priv, nil
+}
+
+//ClearKeyCache 清空私钥派生的密钥缓存，如不再签名时从内存中移除密钥
+func ClearKeyCache() {
+	if cache := getKeyCache(); cache != nil {
+		cache.Clear()
+	}
+}
+
+//IsValidSecret 钱包私钥合法性验证，支持 secp256k1 和 ed25519 私钥。只校验，不缓存派生的密钥
 func IsValidSecret(secret string) bool {
 	if secret == "" {
 		return false
 	}
 
-	keyPair := &secp256k1.Secp256KeyPair{}
-	_, err := keyPair.DeriveKeyPair(secret)
+	_, err := deriveKey(secret)
 	if nil != err {
 		return false
 	}
@@ -47,9 +131,18 @@ func IsValidSecret(secret string) bool {
 	return true
 }
 
-//Generate 生成钱包
-func Generate() (*Wallet, error) {
-	keyPair := &secp256k1.Secp256KeyPair{}
+//Generate 生成钱包，可指定签名算法 crypto.Secp256k1（默认）或 crypto.Ed25519
+func Generate(keyType ...crypto.KeyType) (*Wallet, error) {
+	kt := crypto.Secp256k1
+	if len(keyType) > 0 {
+		kt = keyType[0]
+	}
+
+	keyPair, ok := keyPairs[kt]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported key type %s", kt)
+	}
+
 	secret, err := keyPair.GenerateSeed()
 	if err != nil {
 		return nil, err
@@ -58,13 +151,12 @@ func Generate() (*Wallet, error) {
 	return FromSecret(secret)
 }
 
-//FromSecret 根据井通私钥创建钱包
+//FromSecret 根据井通私钥创建钱包，根据私钥前缀自动识别签名算法
 func FromSecret(secret string) (*Wallet, error) {
 	if secret == "" {
 		return nil, constant.ERR_EMPTY_PARAM
 	}
-	keyPair := &secp256k1.Secp256KeyPair{}
-	priv, err := keyPair.DeriveKeyPair(secret)
+	priv, err := cachedKey(secret)
 	if nil != err {
 		return nil, err
 	}
@@ -74,36 +166,72 @@ func FromSecret(secret string) (*Wallet, error) {
 	return wallet, nil
 }
 
+//FromMnemonic 根据助记词恢复钱包，助记词为 GetMnemonic 得到的 12 个单词。
+//助记词不含签名算法，可指定 crypto.Secp256k1（默认）或 crypto.Ed25519，与 Generate 时一致
+func FromMnemonic(phrase string, keyType ...crypto.KeyType) (*Wallet, error) {
+	if phrase == "" {
+		return nil, constant.ERR_EMPTY_PARAM
+	}
+
+	entropy, err := mnemonic.MnemonicToEntropy(phrase)
+	if err != nil {
+		return nil, err
+	}
+
+	kt := crypto.Secp256k1
+	if len(keyType) > 0 {
+		kt = keyType[0]
+	}
+
+	secret, err := crypto.EncodeSecret(entropy, kt)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSecret(secret)
+}
+
 //GetPublicKey 获取16进制公钥
 func (wallet *Wallet) GetPublicKey() string {
-	return wallet.priv.PublicKey.BytesToHex()
+	return wallet.priv.BytesToHex()
 }
 
-//GetSecret 获取私钥
+//GetSecret 获取私钥，账户族派生的账户（序号大于 0）没有私钥，返回空字符串
 func (wallet *Wallet) GetSecret() string {
 	return wallet.secret
 }
 
 //GetAddress 获取钱包地址
 func (wallet *Wallet) GetAddress() string {
-	return wallet.priv.PublicKey.ToAddress()
+	return wallet.priv.ToAddress()
 }
 
-//signTx 对交易数据签名
-func (wallet *Wallet) signTx(hash []byte) (string, error) {
-	priv := &ecdsa.PrivateKey{
-		PublicKey: ecdsa.PublicKey{
-			Curve: btcec.S256(),
-			X:     wallet.priv.X,
-			Y:     wallet.priv.Y,
-		},
-		D: wallet.priv.D,
+//GetMnemonic 获取私钥的助记词，即私钥 16 字节熵对应的 12 个英文单词（BIP39 词表，含校验码），
+//用于人工抄写备份，由 FromMnemonic 恢复
+func (wallet *Wallet) GetMnemonic() (string, error) {
+	if wallet.secret == "" {
+		return "", constant.ERR_WALLET_NO_SECRET
+	}
+
+	entropy, _, err := crypto.SecretEntropy(wallet.secret)
+	if err != nil {
+		return "", err
 	}
 
-	signature, err := (*btcec.PrivateKey)(priv).Sign(hash)
+	return mnemonic.EntropyToMnemonic(entropy)
+}
+
+//GetKeyType 获取签名算法类型
+func (wallet *Wallet) GetKeyType() crypto.KeyType {
+	return wallet.priv.KeyType()
+}
+
+//Sign 对交易签名数据（哈希前缀 + 序列化数据）签名，返回 16 进制签名，Wallet 实现了 Signer 接口
+func (wallet *Wallet) Sign(message []byte) (string, error) {
+	signature, err := wallet.priv.Sign(message)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%X", signature.Serialize()), nil
+	return fmt.Sprintf("%X", signature), nil
 }
@@ -10,7 +10,11 @@
 package jingtumlib
 
 import (
+	stded25519 "crypto/ed25519"
+	"encoding/hex"
 	"testing"
+
+	"jingtumlib/crypto"
 )
 
 /**
@@ -74,6 +78,94 @@ func Test_FromSecret(t *testing.T) {
 	t.Logf("Success FromSecret(%s). PublicKey : %s. Wallet address : %s", wt.GetSecret(), wt.GetPublicKey(), wt.GetAddress())
 }
 
+/**
+ * ed25519 钱包测试用例
+ */
+func Test_WalletEd25519(t *testing.T) {
+	secret := "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"
+	if !IsValidSecret(secret) {
+		t.Fatalf("IsValidSecret(%s) is false", secret)
+	}
+
+	//根据私钥前缀自动识别算法
+	wt, err := FromSecret(secret)
+	if err != nil {
+		t.Fatalf("FromSecret : %s, err %v", secret, err)
+	}
+
+	if wt.GetKeyType() != crypto.Ed25519 || wt.GetAddress() != "jLUEXYuLiQptky37CqLcm9USQpPiz5jkpD" {
+		t.Fatalf("FromSecret(%s) key type %s, address %s", secret, wt.GetKeyType(), wt.GetAddress())
+	}
+
+	message := []byte("STX message")
+	signature, err := wt.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign fail : %s", err.Error())
+	}
+
+	sig, _ := hex.DecodeString(signature)
+	pub, _ := hex.DecodeString(wt.GetPublicKey())
+	if !stded25519.Verify(stded25519.PublicKey(pub[1:]), message, sig) {
+		t.Fatalf("Verify signature %s fail", signature)
+	}
+
+	newWallet, err := Generate(crypto.Ed25519)
+	if err != nil || newWallet.GetKeyType() != crypto.Ed25519 || !IsValidAddress(newWallet.GetAddress()) {
+		t.Fatalf("Generate ed25519 wallet fail : %v", err)
+	}
+
+	if _, err := Generate("rsa"); err == nil {
+		t.Fatalf("Generate with unsupported key type should fail")
+	}
+
+	if wt, _ := Generate(); wt.GetKeyType() != crypto.Secp256k1 {
+		t.Fatalf("Default key type is %s", wt.GetKeyType())
+	}
+}
+
+/**
+ * 助记词备份恢复测试用例
+ */
+func Test_WalletMnemonic(t *testing.T) {
+	for _, secret := range []string{"ssc5eiFivvU2otV6bSYmJeZrAsQK3", "sEdSKaCy2JT7JaM7v95H9SxkhP9wS2j"} {
+		wt, _ := FromSecret(secret)
+		phrase, err := wt.GetMnemonic()
+		if err != nil {
+			t.Fatalf("GetMnemonic fail : %s", err.Error())
+		}
+
+		restored, err := FromMnemonic(phrase, wt.GetKeyType())
+		if err != nil {
+			t.Fatalf("FromMnemonic(%s) fail : %s", phrase, err.Error())
+		}
+
+		if restored.GetSecret() != secret || restored.GetAddress() != wt.GetAddress() {
+			t.Fatalf("FromMnemonic(%s) secret %s, address %s", phrase, restored.GetSecret(), restored.GetAddress())
+		}
+	}
+
+	//ed25519 私钥熵为 0x01..0x10
+	wt, err := FromMnemonic("absurd avoid scissors anxiety gather lottery category door army half long camera", crypto.Ed25519)
+	if err != nil || wt.GetAddress() != "jLUEXYuLiQptky37CqLcm9USQpPiz5jkpD" {
+		t.Fatalf("FromMnemonic ed25519 wallet %v, err %v", wt, err)
+	}
+
+	newWallet, _ := Generate()
+	phrase, _ := newWallet.GetMnemonic()
+	if restored, err := FromMnemonic(phrase); err != nil || restored.GetAddress() != newWallet.GetAddress() {
+		t.Fatalf("FromMnemonic(%s) err %v", phrase, err)
+	}
+
+	//抄错单词顺序时校验失败
+	if _, err := FromMnemonic("absurd scissors avoid anxiety gather lottery category door army half long camera", crypto.Ed25519); err == nil {
+		t.Fatalf("Swapped mnemonic should fail")
+	}
+
+	if _, err := FromMnemonic(phrase, "rsa"); err == nil {
+		t.Fatalf("FromMnemonic with unsupported key type should fail")
+	}
+}
+
 /*
 *以下为request性能测试用例
  */
@@ -139,3 +231,56 @@ func BenchmarkFromSecret(b *testing.B) {
 		b.Logf("Success FromSecret(%s). PublicKey : %s. Wallet address : %s", wt.GetSecret(), wt.GetPublicKey(), wt.GetAddress())
 	}
 }
+
+//Test_KeyCache 同一私钥的密钥只派生一次，清空缓存后重新派生
+func Test_KeyCache(t *testing.T) {
+	secret := "ssc5eiFivvU2otV6bSYmJeZrAsQK3"
+	ClearKeyCache()
+
+	first, _ := FromSecret(secret)
+	second, _ := FromSecret(secret)
+	if first.priv != second.priv {
+		t.Fatalf("Key is not cached")
+	}
+
+	ClearKeyCache()
+	third, _ := FromSecret(secret)
+	if third.priv == first.priv || third.GetAddress() != first.GetAddress() {
+		t.Fatalf("Key is not derived again")
+	}
+
+	if IsValidSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK0") {
+		t.Fatalf("Invalid secret is valid")
+	}
+
+	//IsValidSecret 不缓存密钥
+	ClearKeyCache()
+	if !IsValidSecret(secret) || getKeyCache().Len() != 0 {
+		t.Fatalf("IsValidSecret cached the key")
+	}
+
+	//0 关闭缓存
+	defer SetKeyCacheSize(DefaultKeyCacheSize)
+	if err := SetKeyCacheSize(0); err != nil {
+		t.Fatalf("SetKeyCacheSize fail : %s", err.Error())
+	}
+	first, _ = FromSecret(secret)
+	second, _ = FromSecret(secret)
+	if first.priv == second.priv || first.GetAddress() != second.GetAddress() {
+		t.Fatalf("Key is cached with size 0")
+	}
+
+	if err := SetKeyCacheSize(-1); err == nil {
+		t.Fatalf("SetKeyCacheSize(-1) should fail")
+	}
+}
+
+//BenchmarkFromSecretUncached 每次清空缓存，重新派生 secp256k1 密钥，对比 BenchmarkFromSecret
+func BenchmarkFromSecretUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ClearKeyCache()
+		if _, err := FromSecret("ssc5eiFivvU2otV6bSYmJeZrAsQK3"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/***  远程签名服务
+ *** main.go
+ *** 从钱包文件目录加载私钥，在本地 Unix socket（或 HTTP 地址）上提供签名服务，
+ *** 业务进程通过 jingtumLib.NewRemoteSigner 签名，私钥不进入业务进程。
+ *** 钱包文件口令从环境变量 JTSIGNER_PASSWORD 或标准输入读取。
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	jingtum "jingtumlib"
+)
+
+func main() {
+	keystore := flag.String("keystore", "keystore", "钱包文件目录")
+	socket := flag.String("socket", "jtsigner.sock", "监听的 Unix socket 路径")
+	listen := flag.String("http", "", "监听的 HTTP 地址，如 127.0.0.1:8001，设置后不监听 Unix socket")
+	flag.Parse()
+
+	password := os.Getenv("JTSIGNER_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Keystore password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(os.Stderr, "Read password error :", err)
+			os.Exit(1)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	store, err := jingtum.NewKeystoreDir(*keystore)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Open keystore error :", err)
+		os.Exit(1)
+	}
+
+	addresses, err := store.Addresses()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "List keystore error :", err)
+		os.Exit(1)
+	}
+
+	wallets := make([]*jingtum.Wallet, 0, len(addresses))
+	for _, address := range addresses {
+		wallet, err := store.Load(address, password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Load %s error : %s\n", address, err)
+			os.Exit(1)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	if len(wallets) == 0 {
+		fmt.Fprintln(os.Stderr, "No wallet in", *keystore)
+		os.Exit(1)
+	}
+
+	var listener net.Listener
+	if *listen != "" {
+		listener, err = net.Listen("tcp", *listen)
+	} else {
+		//删除上次异常退出留下的 socket 文件，socket 只允许本用户访问
+		os.Remove(*socket)
+		listener, err = net.Listen("unix", *socket)
+		if err == nil {
+			err = os.Chmod(*socket, 0600)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Listen error :", err)
+		os.Exit(1)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	fmt.Printf("Signing for %s on %s\n", strings.Join(addresses, ", "), listener.Addr())
+	if err := http.Serve(listener, jingtum.NewSignerServer(wallets...)); err != nil && !errors.Is(err, net.ErrClosed) {
+		fmt.Fprintln(os.Stderr, "Serve error :", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,108 @@
+/***  靓号地址生成
+ *** main.go
+ *** 多个协程随机生成钱包，直到地址符合指定的前缀、后缀或正则表达式，每秒在标准错误输出进度，Ctrl+C 取消。
+ *** 设置 -keystore 时用口令加密保存到钱包文件目录（可由 jtsigner 加载），口令从环境变量 JTVANITY_PASSWORD 或标准输入读取；
+ *** 否则在标准输出打印地址和私钥。
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	jingtum "jingtumlib"
+	"jingtumlib/crypto"
+)
+
+func main() {
+	prefix := flag.String("prefix", "", "地址前缀，须以 j 开头")
+	suffix := flag.String("suffix", "", "地址后缀")
+	pattern := flag.String("regex", "", "地址须匹配的正则表达式")
+	keyType := flag.String("type", string(crypto.Secp256k1), "签名算法，secp256k1 或 ed25519")
+	workers := flag.Int("workers", 0, "协程数，默认为 CPU 数")
+	keystore := flag.String("keystore", "", "钱包文件目录，设置后加密保存钱包，不打印私钥")
+	flag.Parse()
+
+	search, err := jingtum.NewVanitySearch(jingtum.VanityOptions{
+		Prefix:  *prefix,
+		Suffix:  *suffix,
+		Pattern: *pattern,
+		KeyType: crypto.KeyType(*keyType),
+		Workers: *workers,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid options :", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var store *jingtum.KeystoreDir
+	var password string
+	if *keystore != "" {
+		store, err = jingtum.NewKeystoreDir(*keystore)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Open keystore error :", err)
+			os.Exit(1)
+		}
+
+		password = os.Getenv("JTVANITY_PASSWORD")
+		if password == "" {
+			fmt.Fprint(os.Stderr, "Keystore password: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Fprintln(os.Stderr, "Read password error :", err)
+				os.Exit(1)
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Fprintf(os.Stderr, "\r%d attempts, %.0f/s", search.Attempts(), search.Rate())
+			}
+		}
+	}()
+
+	wallet, err := search.Run(ctx)
+	close(done)
+	fmt.Fprintf(os.Stderr, "\r%d attempts, %.0f/s\n", search.Attempts(), search.Rate())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Search stopped :", err)
+		os.Exit(1)
+	}
+
+	if store != nil {
+		if err := store.Store(wallet, password); err != nil {
+			fmt.Fprintln(os.Stderr, "Store wallet error :", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Address : %s\nStored in %s\n", wallet.GetAddress(), *keystore)
+		return
+	}
+
+	fmt.Printf("Address : %s\nSecret : %s\n", wallet.GetAddress(), wallet.GetSecret())
+}